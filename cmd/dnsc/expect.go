@@ -0,0 +1,50 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package main
+
+import "github.com/atc0005/dnsc/internal/dqrs"
+
+// Exit codes used to report the outcome of checking answers against expected
+// values or waiting for DNS servers to converge. Exit code 1 is used for
+// general failures.
+const (
+
+	// exitCodeMismatch indicates that no DNS server returned the expected
+	// values.
+	exitCodeMismatch int = 2
+
+	// exitCodePartialPropagation indicates that some DNS servers returned
+	// the expected values while others did not.
+	exitCodePartialPropagation int = 3
+
+	// exitCodeQueryError indicates that all answers matched the expected
+	// values, but one or more queries failed.
+	exitCodeQueryError int = 4
+
+	// exitCodeInconsistent indicates that not all DNS servers converged
+	// before the wait deadline was reached. The outcome of checking answers
+	// against expected values takes precedence.
+	exitCodeInconsistent int = 5
+)
+
+// expectationExitCode returns the exit code for the given outcome of
+// checking answers against expected values. Answers which do not match the
+// expected values take precedence over failed queries.
+func expectationExitCode(summary dqrs.ExpectationSummary) int {
+
+	switch {
+	case summary.Mismatched > 0 && summary.Passed > 0:
+		return exitCodePartialPropagation
+	case summary.Mismatched > 0:
+		return exitCodeMismatch
+	case summary.Errors > 0:
+		return exitCodeQueryError
+	default:
+		return 0
+	}
+}
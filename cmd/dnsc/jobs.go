@@ -0,0 +1,252 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/atc0005/dnsc/internal/config"
+	"github.com/atc0005/dnsc/internal/dqrs"
+	"github.com/atc0005/dnsc/internal/engine"
+	"github.com/miekg/dns"
+
+	"github.com/apex/log"
+)
+
+// resolveServers resolves DNS server entries specified by hostname once up
+// front, expanding each into a separate entry for each of its IP Addresses.
+// Entries which could not be resolved are returned as-is along with the
+// resolution error for each, indexed by server ID, so that the failure is
+// reported for that DNS server without blocking queries against the
+// remaining DNS servers.
+func resolveServers(cfg *config.Config) ([]config.Server, map[string]error) {
+
+	servers := make([]config.Server, 0, len(cfg.Servers()))
+	resolveErrors := make(map[string]error)
+
+	for _, server := range cfg.Servers() {
+		if !server.NeedsResolution() {
+			servers = append(servers, server)
+			continue
+		}
+
+		ipAddresses, err := dqrs.ResolveHost(server.Host, cfg.BootstrapServer(), cfg.Timeout())
+		if err != nil {
+			servers = append(servers, server)
+			resolveErrors[server.ID()] = fmt.Errorf("failed to resolve DNS server entry %q: %w", server.Spec, err)
+			continue
+		}
+
+		log.Debugf("Resolved DNS server %q to %v", server.Host, ipAddresses)
+		for _, ipAddress := range ipAddresses {
+			servers = append(servers, server.Resolved(ipAddress))
+		}
+	}
+
+	return servers, resolveErrors
+}
+
+// queryJobs builds the collection of query jobs to submit for the given
+// query requests; each query name is submitted to every server for every
+// query type requested for that name. Any query which could not be prepared,
+// including queries for DNS servers with the given resolution errors, is
+// returned as a minimal DNSQueryResponse with the specific error embedded.
+func queryJobs(
+	cfg *config.Config,
+	servers []config.Server,
+	resolveErrors map[string]error,
+	queryRequests []config.QueryRequest,
+	queryOptions dqrs.QueryOptions,
+) ([]engine.Job, dqrs.DNSQueryResponses) {
+
+	var jobs []engine.Job
+	var failedQueries dqrs.DNSQueryResponses
+
+	for _, server := range servers {
+
+		// Apply per-server settings, using global settings if not specified
+		// for this server.
+		serverOptions := queryOptions
+		if server.Transport != "" {
+			serverOptions.Transport = server.Transport
+		}
+		serverOptions.TLSServerName = cfg.TLSServerName(server)
+
+		// Record the error, log the error and return a minimal
+		// DNSQueryResponse with the specific error embedded.
+		recordFailure := func(query string, rrType uint16, err error) {
+			failedQuery := dqrs.DNSQueryResponse{
+				Server:              server.Address(),
+				ServerID:            server.ID(),
+				ServerLabel:         server.Label,
+				ServerHostname:      server.Hostname,
+				Query:               query,
+				RequestedRecordType: rrType,
+				QueryError:          err,
+			}
+			log.Warn(failedQuery.Error())
+			failedQueries = append(failedQueries, failedQuery)
+		}
+
+		resolveErr := resolveErrors[server.ID()]
+
+		for _, request := range queryRequests {
+
+			query := request.Name
+
+			log.Debugf("Length of requested query types for %q: %d", query, len(request.Types))
+			for _, rrString := range request.Types {
+				rrType, err := dqrs.RRStringToType(rrString)
+				if err != nil {
+					recordFailure(query, 0, fmt.Errorf("error converting Resource Record string to native type: %w", err))
+					continue
+				}
+
+				// Report the resolution failure for each query which would
+				// have been submitted to this DNS server.
+				if resolveErr != nil {
+					recordFailure(query, rrType, resolveErr)
+					continue
+				}
+
+				var queries []string
+				switch {
+
+				// If performing SRV record queries, check to see if SRV
+				// protocols were specified. If so, submit a separate query
+				// for each one after resolving the protocol record syntax
+				// needed.
+				case rrType == dns.TypeSRV && len(cfg.SrvProtocols()) > 0:
+					for _, srvProtocol := range cfg.SrvProtocols() {
+						queryTemplate, err := config.SrvProtocolTmplLookup(srvProtocol)
+						if err != nil {
+							recordFailure(query, rrType, fmt.Errorf("error retrieving SRV protocol template: %w", err))
+							continue
+						}
+						queries = append(queries, fmt.Sprintf(queryTemplate, query))
+					}
+
+				// use the query string as-is if SRV protocols not specified
+				default:
+					queries = append(queries, query)
+				}
+
+				for _, q := range queries {
+					jobs = append(jobs, engine.Job{
+						Query:          q,
+						Server:         server.Address(),
+						ServerID:       server.ID(),
+						ServerLabel:    server.Label,
+						ServerHostname: server.Hostname,
+						RecordType:     rrType,
+						RateLimit:      cfg.ServerRateLimit(server),
+						Options:        serverOptions,
+					})
+				}
+			}
+		}
+	}
+
+	log.Debugf("Total queries collected: %d", len(jobs))
+
+	return jobs, failedQueries
+}
+
+// runJobs submits the given query jobs and collects the responses along with
+// the given queries which could not be prepared. The responses are sorted
+// for display. If the user opted to treat DNS errors as fatal, the responses
+// collected so far are displayed and the application exits on the first
+// error.
+func runJobs(
+	cfg *config.Config,
+	jobs []engine.Job,
+	failedQueries dqrs.DNSQueryResponses,
+	summaryOptions dqrs.SummaryOptions,
+) dqrs.DNSQueryResponses {
+
+	expectedResponses := len(jobs) + len(failedQueries)
+
+	log.Debugf("%d queries to submit, equal number responses expected\n", expectedResponses)
+
+	results := make(dqrs.DNSQueryResponses, 0, expectedResponses)
+	resultsChan := make(chan dqrs.DNSQueryResponse)
+
+	var collectorWG sync.WaitGroup
+
+	// spin off "collector" for results channel
+	collectorWG.Add(1)
+	go func() {
+		defer collectorWG.Done()
+		// Collect all responses
+		for result := range resultsChan {
+
+			log.Debug("collector: Received result")
+
+			results = append(results, result)
+			if result.QueryError != nil {
+				// Check whether the user has opted to treat errors as fatal. If
+				// so, display current summary results and exit
+				if cfg.DNSErrorsFatal() {
+					results.PrintSummary(summaryOptions)
+					os.Exit(1)
+				}
+			}
+
+			log.Debug("collector: Saved result")
+		}
+
+		log.Debug("collector: Exited collection loop")
+	}()
+
+	// Report queries which could not be prepared alongside the responses
+	// for submitted queries.
+	for _, failedQuery := range failedQueries {
+		resultsChan <- failedQuery
+	}
+
+	// Submit queries using a bounded pool of workers, send back results on a
+	// channel
+	queryEngine := engine.New(cfg.MaxConcurrency(), cfg.MaxPerServer(), cfg.RateLimit())
+	queryEngine.Run(jobs, resultsChan)
+
+	// Close results channel after all queries have been submitted and results
+	// are ready to be collected
+	close(resultsChan)
+
+	// Wait on collector to finish saving results from earlier queries
+	collectorWG.Wait()
+
+	sortResults(results)
+
+	return results
+}
+
+// sortResults sorts DNS query results by server used for query. This is done
+// in an effort to arrange responses based on the group of DNS servers
+// (assuming that they're grouped together using a consecutive IP block).
+// Results for the same server are arranged by server entry (e.g., the same
+// address specified using different transports), by query and then by query
+// type so that results for multiple queries are presented as a consolidated
+// summary.
+func sortResults(results dqrs.DNSQueryResponses) {
+	sort.Slice(results, func(i, j int) bool {
+		switch {
+		case results[i].Server != results[j].Server:
+			return results[i].Server < results[j].Server
+		case results[i].ServerID != results[j].ServerID:
+			return results[i].ServerID < results[j].ServerID
+		case results[i].Query != results[j].Query:
+			return results[i].Query < results[j].Query
+		default:
+			return results[i].RequestedRecordType < results[j].RequestedRecordType
+		}
+	})
+}
@@ -11,15 +11,10 @@ package main
 
 import (
 	"errors"
-	"fmt"
 	"os"
-	"sort"
-	"sync"
-	"time"
 
 	"github.com/atc0005/dnsc/internal/config"
 	"github.com/atc0005/dnsc/internal/dqrs"
-	"github.com/miekg/dns"
 
 	"github.com/apex/log"
 )
@@ -40,165 +35,93 @@ func main() {
 		log.Fatalf("failed to initialize application: %s", cfgErr)
 	}
 
-	// Get a list of all record types that we should request when submitting
-	// DNS queries
-	queryTypes := cfg.QueryTypes()
-	queryTimeout := cfg.Timeout()
-
-	var expectedResponses int
-	switch {
-	case len(cfg.SrvProtocols()) > 0:
-		types := len(queryTypes) - 1         // exclude SRV record query type
-		protocols := len(cfg.SrvProtocols()) // SRV query type calculated here
-		servers := len(cfg.Servers())
-
-		expectedResponses = (types * servers) + (protocols * servers)
-	default:
-		expectedResponses = len(queryTypes) * len(cfg.Servers())
+	tlsConfig, tlsErr := dqrs.NewTLSConfig(cfg.TLSCAFile(), cfg.TLSInsecureSkipVerify())
+	if tlsErr != nil {
+		log.Fatalf("failed to initialize TLS configuration: %s", tlsErr)
 	}
 
-	log.Debugf("%d queries to submit, equal number responses expected\n", expectedResponses)
+	queryOptions := dqrs.QueryOptions{
+		Timeout:      cfg.Timeout(),
+		Transport:    cfg.Transport(),
+		TLSConfig:    tlsConfig,
+		DoHMethod:    cfg.DoHMethod(),
+		Retries:      cfg.Retries(),
+		RetryBackoff: cfg.RetryBackoff(),
+		RetryJitter:  cfg.RetryJitter(),
+	}
 
-	results := make(dqrs.DNSQueryResponses, 0, expectedResponses)
-	resultsChan := make(chan dqrs.DNSQueryResponse)
+	servers, resolveErrors := resolveServers(cfg)
 
-	var queriesWG sync.WaitGroup
-	var collectorWG sync.WaitGroup
+	jobs, failedQueries := queryJobs(cfg, servers, resolveErrors, cfg.QueryRequests(), queryOptions)
+	summaryOptions := dqrs.SummaryOptions{
+		OutputFormat:  cfg.ResultsOutput(),
+		OmitTimestamp: cfg.OmitTimestamp(),
+		ShowFlags:     cfg.ShowFlags(),
+		ShowSections:  cfg.ShowSections(),
+	}
 
-	// spin off "collector" for results channel
-	collectorWG.Add(1)
-	go func() {
-		defer collectorWG.Done()
-		// Collect all responses
-		for result := range resultsChan {
+	if cfg.OutputTemplate() != "" {
+		outputTemplate, err := dqrs.NewOutputTemplate(cfg.OutputTemplate())
+		if err != nil {
+			log.Fatalf("failed to initialize output template: %s", err)
+		}
+		summaryOptions.Template = outputTemplate
+	}
 
-			log.Debug("collector: Received result")
+	expectations, expectErr := dqrs.NewExpectations(cfg.Expect())
+	if expectErr != nil {
+		log.Fatalf("failed to initialize expected values: %s", expectErr)
+	}
 
-			results = append(results, result)
-			if result.QueryError != nil {
-				// Check whether the user has opted to treat errors as fatal. If
-				// so, display current summary results and exit
-				if cfg.DNSErrorsFatal() {
-					results.PrintSummary(cfg.ResultsOutput(), cfg.OmitTimestamp())
-					os.Exit(1)
-				}
-			}
+	// If requested, repeatedly run the same queries until interrupted.
+	if cfg.Watch() > 0 {
+		watch(cfg, jobs, failedQueries, expectations, summaryOptions)
+	}
 
-			log.Debug("collector: Saved result")
-		}
+	results := runJobs(cfg, jobs, failedQueries, summaryOptions)
 
-		log.Debug("collector: Exited collection loop")
-	}()
-
-	// spin off a separate goroutine for each of our DNS servers, send back
-	// results on a channel
-	for _, server := range cfg.Servers() {
-
-		queriesWG.Add(1)
-		go func(server string, query string, queryTypes []string, queryTimeout time.Duration, results chan dqrs.DNSQueryResponse) {
-
-			defer queriesWG.Done()
-
-			log.Debugf("Length of requested query types: %d", len(queryTypes))
-			for _, rrString := range queryTypes {
-				rrType, err := dqrs.RRStringToType(rrString)
-				if err != nil {
-					// Record the error, log the error and send a minimal
-					// DNSQueryResponse type back on the channel with the
-					// specific error embedded.
-					failedQueryRequest := dqrs.DNSQueryResponse{
-						Server:     server,
-						Query:      query,
-						QueryError: fmt.Errorf("error converting Resource Record string to native type: %w", err),
-					}
-					log.Warn(failedQueryRequest.Error())
-					queriesWG.Add(1)
-					go func() {
-						defer queriesWG.Done()
-						results <- failedQueryRequest
-					}()
-
-				}
-
-				var totalQueries int
-				switch {
-				case rrType == dns.TypeSRV && len(cfg.SrvProtocols()) > 0:
-					totalQueries = len(cfg.SrvProtocols())
-				default:
-					totalQueries = 1
-				}
-				queries := make([]string, 0, totalQueries)
-
-				switch {
-
-				// If performing SRV record queries, check to see if SRV
-				// protocols were specified. If so, submit a separate query
-				// for each one after resolving the protocol record syntax
-				// needed.
-				case rrType == dns.TypeSRV && len(cfg.SrvProtocols()) > 0:
-					for _, srvProtocol := range cfg.SrvProtocols() {
-						queryTemplate, err := config.SrvProtocolTmplLookup(srvProtocol)
-						if err != nil {
-							// Record the error, log the error and send a
-							// minimal DNSQueryResponse type back on the
-							// channel with the specific error embedded.
-							failedQueryRequest := dqrs.DNSQueryResponse{
-								Server:     server,
-								Query:      query,
-								QueryError: fmt.Errorf("error retrieving SRV protocol template: %w", err),
-							}
-							log.Warn(failedQueryRequest.Error())
-							queriesWG.Add(1)
-							go func() {
-								defer queriesWG.Done()
-								results <- failedQueryRequest
-							}()
-
-						}
-						queries = append(queries, fmt.Sprintf(queryTemplate, query))
-					}
-				// use the query string as-is if SRV protocols not specified
-				default:
-					queries = append(queries, query)
-				}
-
-				log.Debugf("Total queries collected: %d", len(queries))
-
-				for i := range queries {
-					log.Debugf("Submitting query for %q of type %q to %q",
-						queries[i], rrString, server)
-
-					queriesWG.Add(1)
-					go func(q string) {
-						defer queriesWG.Done()
-						results <- dqrs.PerformQuery(q, server, rrType, queryTimeout)
-						log.Debug("Query completed, results sent back on channel")
-					}(queries[i])
-
-				}
+	// If requested, resubmit queries to DNS servers which have not yet
+	// converged until all have or the deadline is reached.
+	var timeline *dqrs.ConsistencyTimeline
+	if cfg.WaitForConsistency() {
+		timeline = waitForConsistency(cfg, jobs, results, expectations, summaryOptions)
+		results = timeline.Latest()
+		sortResults(results)
+	}
 
+	// Generate summary of all collected query responses in the specified
+	// format
+	results.PrintSummary(summaryOptions)
+
+	var exitCode int
+	if timeline != nil {
+		converged := results.Converged(expectations)
+		timeline.PrintTimeline(converged, summaryOptions)
+		for _, ok := range converged {
+			if !ok {
+				exitCode = exitCodeInconsistent
 			}
-		}(server, cfg.Query(), queryTypes, queryTimeout, resultsChan)
-
+		}
 	}
 
-	// Close results channel after all queries have been submitted and results
-	// are ready to be collected
-	queriesWG.Wait()
-	close(resultsChan)
+	if cfg.PropagationETA() {
+		results.PrintPropagationETA(expectations, summaryOptions)
+	}
 
-	// Wait on collector to finish saving results from earlier queries
-	collectorWG.Wait()
+	// If requested, check the answer from each DNS server against the
+	// expected values and report the outcome via exit code.
+	if len(expectations) > 0 {
+		summary := results.CheckExpectations(expectations)
+		if summary == (dqrs.ExpectationSummary{}) {
+			log.Warn("No queries were submitted for record types with expected values")
+		}
 
-	// Sort DNS query results by server used for query. This is done in an
-	// effort to arrange responses based on the group of DNS servers (assuming
-	// that they're grouped together using a consecutive IP block)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Server < results[j].Server
-	})
+		results.PrintExpectations(expectations, summaryOptions)
+		if expectationExitCode(summary) != 0 {
+			exitCode = expectationExitCode(summary)
+		}
+	}
 
-	// Generate summary of all collected query responses in the specified
-	// format
-	results.PrintSummary(cfg.ResultsOutput(), cfg.OmitTimestamp())
+	os.Exit(exitCode)
 
 }
@@ -0,0 +1,79 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package main
+
+import (
+	"time"
+
+	"github.com/atc0005/dnsc/internal/config"
+	"github.com/atc0005/dnsc/internal/dqrs"
+	"github.com/atc0005/dnsc/internal/engine"
+
+	"github.com/apex/log"
+)
+
+// waitForConsistency resubmits queries at each poll interval to DNS servers
+// which have not yet converged on the final answer (or matched the expected
+// values) until all have converged or the wait deadline is reached. The
+// given results from the initial poll are used as the starting point. The
+// timeline of answers provided by each DNS server is returned.
+func waitForConsistency(
+	cfg *config.Config,
+	jobs []engine.Job,
+	results dqrs.DNSQueryResponses,
+	expectations dqrs.Expectations,
+	summaryOptions dqrs.SummaryOptions,
+) *dqrs.ConsistencyTimeline {
+
+	start := time.Now()
+	deadline := start.Add(cfg.WaitDeadline())
+
+	timeline := dqrs.NewConsistencyTimeline(start)
+	timeline.Record(start, results)
+
+	for poll := 1; ; poll++ {
+		converged := timeline.Latest().Converged(expectations)
+
+		pending := make([]engine.Job, 0, len(jobs))
+		for _, job := range jobs {
+			key := dqrs.ResponseKey{
+				Server:     job.ServerID,
+				Query:      job.Query,
+				RecordType: job.RecordType,
+			}
+			if !converged[key] {
+				pending = append(pending, job)
+			}
+		}
+
+		log.Infof(
+			"Poll %d: %d of %d queries converged (elapsed: %v)",
+			poll,
+			len(jobs)-len(pending),
+			len(jobs),
+			time.Since(start).Round(time.Second),
+		)
+
+		if len(pending) == 0 {
+			return timeline
+		}
+
+		if time.Now().Add(cfg.PollInterval()).After(deadline) {
+			log.Warnf(
+				"Wait deadline of %v reached; %d queries have not converged",
+				cfg.WaitDeadline(),
+				len(pending),
+			)
+			return timeline
+		}
+
+		time.Sleep(cfg.PollInterval())
+
+		timeline.Record(time.Now(), runJobs(cfg, pending, nil, summaryOptions))
+	}
+}
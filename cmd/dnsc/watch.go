@@ -0,0 +1,85 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/atc0005/dnsc/internal/config"
+	"github.com/atc0005/dnsc/internal/dqrs"
+	"github.com/atc0005/dnsc/internal/engine"
+
+	"github.com/apex/log"
+)
+
+// clearScreen is the ANSI escape sequence used to move the cursor to the top
+// left of the terminal and clear the screen.
+const clearScreen string = "\033[H\033[2J"
+
+// watch repeatedly submits the given query jobs at each watch interval until
+// the application is interrupted. After each run the results summary is
+// redrawn with results which changed since the previous run flagged. The
+// screen is only cleared before redrawing the results summary if a table
+// output format is written to a terminal.
+func watch(
+	cfg *config.Config,
+	jobs []engine.Job,
+	failedQueries dqrs.DNSQueryResponses,
+	expectations dqrs.Expectations,
+	summaryOptions dqrs.SummaryOptions,
+) {
+
+	redraw := summaryOptions.Tabular() && isTerminal(os.Stdout)
+
+	var previous dqrs.DNSQueryResponses
+	for run := 1; ; run++ {
+		started := time.Now()
+
+		results := runJobs(cfg, jobs, failedQueries, summaryOptions)
+
+		if redraw {
+			_, _ = fmt.Fprint(os.Stdout, clearScreen)
+		}
+
+		log.Infof(
+			"Watch run %d; repeating every %v (press Ctrl+C to stop)",
+			run,
+			cfg.Watch(),
+		)
+
+		runOptions := summaryOptions
+		runOptions.Previous = previous
+		results.PrintSummary(runOptions)
+
+		if cfg.PropagationETA() {
+			results.PrintPropagationETA(expectations, runOptions)
+		}
+
+		if len(expectations) > 0 {
+			results.PrintExpectations(expectations, runOptions)
+		}
+
+		previous = results
+
+		time.Sleep(time.Until(started.Add(cfg.Watch())))
+	}
+}
+
+// isTerminal indicates whether the given file is a terminal (character
+// device) instead of a file or pipe.
+func isTerminal(f *os.File) bool {
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
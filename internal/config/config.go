@@ -11,10 +11,13 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/atc0005/dnsc/internal/dqrs"
+
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
 	"github.com/apex/log/handlers/discard"
@@ -35,18 +38,41 @@ const myAppName string = "dnsc"
 const myAppURL string = "https://github.com/atc0005/" + myAppName
 
 const (
-	versionFlagHelp        = "Whether to display application version and then immediately exit application."
-	queryFlagHelp          = "Fully-qualified system to lookup from all provided DNS servers."
-	logLevelFlagHelp       = "Log message priority filter. Log messages with a lower level are ignored."
-	logFormatFlagHelp      = "Log messages are written in this format."
-	dnsErrorsFatalFlagHelp = "Whether DNS-related errors should force this application to immediately exit."
-	omitTimestampFlagHelp  = "Whether the date/time that results are generated is omitted from the results output."
-	configFileFlagHelp     = "Full path to TOML-formatted configuration file. See config.example.toml for a starter template."
-	dnsServerFlagHelp      = "DNS server to submit query against. This flag may be repeated for each additional DNS server to query."
-	dnsRequestTypeFlagHelp = "DNS query type to use when submitting DNS queries. The default is the 'A' query type. This flag may be repeated for each additional DNS record type you wish to request."
-	dnsTimeoutFlagHelp     = "Maximum number of seconds allowed for a DNS query to take before timing out."
-	srvProtocolFlagHelp    = "Service Location (SRV) protocols associated with a given domain name as the query string. For example, \"msdcs\" can be specified as the SRV record protocol along with \"example.com\" as the query string to search DNS for \"_ldap._tcp.dc._msdcs.example.com\". This flag may be repeated for each additional SRV protocol that you wish to request records for."
-	resultsOutputFlagHelp  = "Specifies whether the results summary output is composed of a single comma-separated line of records for a query, or whether the records are returned one per line."
+	versionFlagHelp         = "Whether to display application version and then immediately exit application."
+	queryFlagHelp           = "Fully-qualified system to lookup from all provided DNS servers. This flag may be repeated for each additional system to lookup."
+	logLevelFlagHelp        = "Log message priority filter. Log messages with a lower level are ignored."
+	logFormatFlagHelp       = "Log messages are written in this format."
+	dnsErrorsFatalFlagHelp  = "Whether DNS-related errors should force this application to immediately exit."
+	omitTimestampFlagHelp   = "Whether the date/time that results are generated is omitted from the results output."
+	configFileFlagHelp      = "Full path to TOML-formatted configuration file. See config.example.toml for a starter template."
+	dnsServerFlagHelp       = "DNS server to submit query against, specified as [transport://]host[:port][#label] (e.g., udp://[2001:db8::1]:5353#lab-resolver). DNS-over-HTTPS servers are specified as https:// URLs. This flag may be repeated for each additional DNS server to query."
+	dnsRequestTypeFlagHelp  = "DNS query type to use when submitting DNS queries. Any standard record type (e.g., A, MX, TXT, SOA, CAA) or the generic TYPE### notation may be specified. The default is the 'A' query type. This flag may be repeated for each additional DNS record type you wish to request."
+	dnsTimeoutFlagHelp      = "Maximum number of seconds allowed for a DNS query to take before timing out."
+	srvProtocolFlagHelp     = "Service Location (SRV) protocols associated with a given domain name as the query string. For example, \"msdcs\" can be specified as the SRV record protocol along with \"example.com\" as the query string to search DNS for \"_ldap._tcp.dc._msdcs.example.com\". This flag may be repeated for each additional SRV protocol that you wish to request records for."
+	resultsOutputFlagHelp   = "Specifies whether the results summary output is composed of a single comma-separated line of records for a query, or whether the records are returned one per line. The json format emits all results as a single JSON document intended for further processing. The csv and tsv formats emit a header row and one row for each record for use with spreadsheets. The markdown and html formats emit a shareable report which highlights DNS servers whose answers differ from the majority. Log messages are written to stderr when any of these formats are used."
+	transportFlagHelp       = "Network transport used to submit DNS queries. The 'auto' transport submits queries using UDP and retries using TCP if the response is truncated. The 'tls' transport submits queries using DNS-over-TLS (DoT)."
+	tlsServerNameFlagHelp   = "Name used to verify the certificate presented by a DNS server when using the 'tls' transport, specified as SERVER=NAME. If not specified for a server, the server value is used. This flag may be repeated for each additional DNS server."
+	tlsCAFileFlagHelp       = "Full path to a PEM-formatted CA bundle used to verify DNS server certificates instead of the system certificate pool."
+	tlsInsecureFlagHelp     = "Whether certificate verification is skipped when using an encrypted transport. This is insecure and is intended for testing purposes only."
+	dohMethodFlagHelp       = "HTTP method used to submit DNS-over-HTTPS (DoH) queries to DNS servers specified as https:// URLs."
+	bootstrapServerFlagHelp = "IP Address (and optional port) of the DNS server used to resolve DNS server entries specified by hostname. If not specified, the system resolver is used."
+	queryFileFlagHelp       = "Full path to a file containing queries, one per line. Each line specifies a query name optionally followed by one or more record types which are requested instead of the configured query types. Blank lines and # comments are ignored. Use - to read queries from standard input."
+	maxConcurrencyFlagHelp  = "Maximum number of DNS queries in-flight at any one time across all DNS servers."
+	maxPerServerFlagHelp    = "Maximum number of DNS queries in-flight to any single DNS server at any one time."
+	rateLimitFlagHelp       = "Maximum number of DNS queries per second submitted across all DNS servers. Queries are paced evenly to honor this limit. A value of 0 disables this limit."
+	serverRateLimitFlagHelp = "Maximum number of DNS queries per second submitted to a specific DNS server, specified as SERVER=QPS. This limit applies in addition to the overall rate limit. This flag may be repeated for each additional DNS server."
+	retriesFlagHelp         = "Number of times a DNS query is resubmitted if the query fails (e.g., times out). A value of 0 disables retries. Up to 10 retries may be specified."
+	retryBackoffFlagHelp    = "Number of milliseconds to wait before the first retry of a failed DNS query. This delay is doubled for each additional retry, up to a maximum of 30 seconds."
+	retryJitterFlagHelp     = "Maximum random number of milliseconds added to each retry delay in order to avoid resubmitting queries in lockstep."
+	showFlagsFlagHelp       = "Whether the header flags set in each response (e.g., aa, rd, ra) and whether the answer came from an authoritative DNS server are included in the results output."
+	showSectionsFlagHelp    = "Whether records from the authority and additional sections of each response (e.g., the SOA record provided with a negative answer) are included in the results output along with the section each record was returned in."
+	expectFlagHelp          = "Expected value for a record type, specified as TYPE=VALUE (e.g., A=10.2.3.4). The answer from each DNS server is checked against the expected values for the requested record type and the application exits with a non-zero exit code if any answer does not match. This flag may be repeated for each additional expected value."
+	waitFlagHelp            = "Whether DNS queries are resubmitted at each poll interval until all DNS servers agree on the answer (or match the expected values, if provided) or the wait deadline is reached. Queries are only resubmitted to DNS servers which have not yet converged."
+	pollIntervalFlagHelp    = "Number of seconds between each poll of DNS servers which have not yet converged when waiting for consistency."
+	waitDeadlineFlagHelp    = "Maximum number of seconds to wait for all DNS servers to converge when waiting for consistency."
+	watchFlagHelp           = "Number of seconds between each run of the configured queries when watching for changes. The results summary is redrawn after each run with results which changed since the previous run flagged. A value of 0 disables watch mode."
+	propagationETAFlagHelp  = "Whether the expected refresh time is estimated for each DNS server which provided an outdated answer (one which does not match the expected values, if provided, or the majority answer otherwise) based on the remaining TTL of that answer, along with the latest time by which all queried DNS servers should converge."
+	outputTemplateFlagHelp  = "Go text/template used to generate the results output instead of the selected results output format. The template may be provided inline or read from a file by prefixing the path with @ (e.g., @/path/to/results.tmpl). Log messages are written to stderr when a template is used."
 )
 
 // shorthandFlagSuffix is appended to short flag help text to emphasize that
@@ -55,16 +81,35 @@ const shorthandFlagSuffix = " (shorthand)"
 
 // Default flag settings if not overridden by user input
 const (
-	defaultLogLevel              string = "info"
-	defaultLogFormat             string = "text"
-	defaultDisplayVersionAndExit bool   = false
-	defaultDNSErrorsFatal        bool   = false
-	defaultOmitTimestamp         bool   = false
-	defaultConfigFileName        string = "config.toml"
-	defaultQueryType             string = "A"
-	defaultConfigFile            string = ""
-	defaultQuery                 string = ""
-	defaultResultsOutput         string = ResultsOutputMultiLine
+	defaultLogLevel              string  = "info"
+	defaultLogFormat             string  = "text"
+	defaultDisplayVersionAndExit bool    = false
+	defaultDNSErrorsFatal        bool    = false
+	defaultOmitTimestamp         bool    = false
+	defaultConfigFileName        string  = "config.toml"
+	defaultQueryType             string  = "A"
+	defaultConfigFile            string  = ""
+	defaultResultsOutput         string  = ResultsOutputMultiLine
+	defaultTransport             string  = TransportUDP
+	defaultTLSCAFile             string  = ""
+	defaultTLSInsecure           bool    = false
+	defaultDoHMethod             string  = DoHMethodPost
+	defaultBootstrapServer       string  = ""
+	defaultQueryFile             string  = ""
+	defaultMaxConcurrency        int     = 20
+	defaultMaxPerServer          int     = 5
+	defaultRateLimit             float64 = 0
+	defaultRetries               int     = 0
+	defaultRetryBackoff          int     = 250
+	defaultRetryJitter           int     = 100
+	defaultShowFlags             bool    = false
+	defaultShowSections          bool    = false
+	defaultOutputTemplate        string  = ""
+	defaultWaitForConsistency    bool    = false
+	defaultPollInterval          int     = 10
+	defaultWaitDeadline          int     = 600
+	defaultWatch                 int     = 0
+	defaultPropagationETA        bool    = false
 
 	// the default timeout is set by the `miekg/dns.dnsTimeout` value, which
 	// at the time of this writing is 2 seconds. we override with our own
@@ -73,6 +118,10 @@ const (
 	defaultTimeout int = 10
 )
 
+// maxRetries is the maximum number of times a failed DNS query may be
+// resubmitted.
+const maxRetries int = 10
+
 // Log levels
 const (
 	// https://godoc.org/github.com/apex/log#Level
@@ -95,18 +144,6 @@ const (
 	LogLevelDebug string = "debug"
 )
 
-// Supported Request types
-// TODO: Duplicated in dqrs package
-const (
-	RequestTypeA     string = "A"
-	RequestTypeAAAA  string = "AAAA"
-	RequestTypeCNAME string = "CNAME"
-	RequestTypeMX    string = "MX"
-	RequestTypeNS    string = "NS"
-	RequestTypePTR   string = "PTR"
-	RequestTypeSRV   string = "SRV"
-)
-
 // Supported Service Location (SRV) Protocol keywords
 const (
 	SrvProtocolMSDCS      string = "msdcs"
@@ -154,6 +191,31 @@ const (
 const (
 	ResultsOutputSingleLine string = "single-line"
 	ResultsOutputMultiLine  string = "multi-line"
+	ResultsOutputJSON       string = "json"
+	ResultsOutputCSV        string = "csv"
+	ResultsOutputTSV        string = "tsv"
+	ResultsOutputMarkdown   string = "markdown"
+	ResultsOutputHTML       string = "html"
+)
+
+// Supported transports used to submit DNS queries
+// TODO: Duplicated in dqrs package
+const (
+	TransportUDP  string = "udp"
+	TransportTCP  string = "tcp"
+	TransportAuto string = "auto"
+	TransportTLS  string = "tls"
+
+	// Used only with DNS server entries which specify these transports.
+	TransportHTTPS string = "https"
+	TransportQUIC  string = "quic"
+)
+
+// Supported HTTP methods used to submit DNS-over-HTTPS (DoH) queries
+// TODO: Duplicated in dqrs package
+const (
+	DoHMethodGet  string = "get"
+	DoHMethodPost string = "post"
 )
 
 // multiValueFlag is a custom type that satisfies the flag.Value interface in
@@ -213,6 +275,10 @@ type Config struct {
 	// showVersion is a flag indicating whether the user opted to display only
 	// the version string and then immediately exit the application
 	showVersion bool `toml:"-"`
+
+	// queryFileRequests is the collection of query requests loaded from a
+	// user-specified query file or standard input.
+	queryFileRequests []QueryRequest `toml:"-"`
 }
 
 // configTemplate is our base configuration template used to collect values
@@ -233,9 +299,16 @@ type configTemplate struct {
 	// records are returned one per line.
 	ResultsOutput string `toml:"results_output"`
 
-	// Query represents the FQDN query strings submitted to each DNS server
+	// Query represents a single FQDN query string submitted to each DNS
+	// server. This setting is retained for compatibility with existing
+	// configuration files; see Queries for specifying multiple query
+	// strings.
 	Query string `toml:"query"`
 
+	// Queries is a list of the FQDN query strings submitted to each DNS
+	// server.
+	Queries multiValueFlag `toml:"queries"`
+
 	// Servers is a list of the DNS servers used by this application. Most
 	// commonly set in a configuration file due to the number of servers used
 	// for testing queries.
@@ -265,20 +338,135 @@ type configTemplate struct {
 	// OmitTimestamp specifies whether the date & time for when the output is
 	// generated is omitted from the results.
 	OmitTimestamp bool `toml:"omit_timestamp"`
+
+	// Transport is the network transport used to submit DNS queries. The
+	// automatic transport submits queries using UDP and retries using TCP if
+	// the response is truncated.
+	Transport string `toml:"transport"`
+
+	// TLSServerNames is a list of SERVER=NAME pairs specifying the name used
+	// to verify the certificate presented by a DNS server when using an
+	// encrypted transport.
+	TLSServerNames multiValueFlag `toml:"tls_server_names"`
+
+	// TLSCAFile is the fully-qualified path to a PEM-formatted CA bundle used
+	// to verify DNS server certificates instead of the system certificate
+	// pool.
+	TLSCAFile string `toml:"tls_ca_file"`
+
+	// TLSInsecureSkipVerify specifies whether certificate verification is
+	// skipped when using an encrypted transport.
+	TLSInsecureSkipVerify bool `toml:"tls_insecure_skip_verify"`
+
+	// DoHMethod is the HTTP method used to submit DNS-over-HTTPS (DoH)
+	// queries to DNS servers specified as https:// URLs.
+	DoHMethod string `toml:"doh_method"`
+
+	// BootstrapServer is the IP Address (and optional port) of the DNS
+	// server used to resolve DNS server entries specified by hostname.
+	BootstrapServer string `toml:"bootstrap_server"`
+
+	// QueryFile is the fully-qualified path to a file containing queries,
+	// one per line, or "-" to read queries from standard input.
+	QueryFile string `toml:"query_file"`
+
+	// MaxConcurrency is the maximum number of DNS queries in-flight at any
+	// one time across all DNS servers.
+	MaxConcurrency int `toml:"max_concurrency"`
+
+	// MaxPerServer is the maximum number of DNS queries in-flight to any
+	// single DNS server at any one time.
+	MaxPerServer int `toml:"max_per_server"`
+
+	// RateLimit is the maximum number of DNS queries per second submitted
+	// across all DNS servers.
+	RateLimit float64 `toml:"rate_limit"`
+
+	// ServerRateLimits is a list of SERVER=QPS pairs specifying the maximum
+	// number of DNS queries per second submitted to a specific DNS server.
+	ServerRateLimits multiValueFlag `toml:"server_rate_limits"`
+
+	// Retries is the number of times a DNS query is resubmitted if the query
+	// fails.
+	Retries int `toml:"retries"`
+
+	// RetryBackoff is the number of milliseconds to wait before the first
+	// retry of a failed DNS query. This delay is doubled for each additional
+	// retry.
+	RetryBackoff int `toml:"retry_backoff"`
+
+	// RetryJitter is the maximum random number of milliseconds added to each
+	// retry delay.
+	RetryJitter int `toml:"retry_jitter"`
+
+	// ShowFlags specifies whether the header flags set in each response and
+	// whether the answer came from an authoritative DNS server are included
+	// in the results.
+	ShowFlags bool `toml:"show_flags"`
+
+	// ShowSections specifies whether records from the authority and
+	// additional sections of each response are included in the results.
+	ShowSections bool `toml:"show_sections"`
+
+	// Expect is the collection of expected values keyed by record type. The
+	// answer from each DNS server is checked against the expected values for
+	// the requested record type.
+	Expect expectFlag `toml:"expect"`
+
+	// WaitForConsistency specifies whether DNS queries are resubmitted at
+	// each poll interval until all DNS servers have converged or the wait
+	// deadline is reached.
+	WaitForConsistency bool `toml:"wait_for_consistency"`
+
+	// PollInterval is the number of seconds between each poll of DNS
+	// servers which have not yet converged.
+	PollInterval int `toml:"poll_interval"`
+
+	// WaitDeadline is the maximum number of seconds to wait for all DNS
+	// servers to converge.
+	WaitDeadline int `toml:"wait_deadline"`
+
+	// Watch is the number of seconds between each run of the configured
+	// queries when watching for changes.
+	Watch int `toml:"watch"`
+
+	// PropagationETA specifies whether the expected refresh time is
+	// estimated for each DNS server which provided an outdated answer.
+	PropagationETA bool `toml:"propagation_eta"`
+
+	// OutputTemplate is a Go text/template used to generate the results
+	// output, provided inline or as @ followed by the path to a file
+	// containing the template.
+	OutputTemplate string `toml:"output_template"`
 }
 
 func (c Config) String() string {
 	return fmt.Sprintf(
-		"cliConfig: { Servers: %v, Query: %q, LogLevel: %s, LogFormat: %s, "+
+		"cliConfig: { Servers: %v, Queries: %v, LogLevel: %s, LogFormat: %s, "+
 			"ResultsOutput: %s, DNSErrorsFatal: %v, OmitTimestamp: %v, "+
-			"QueryTypes: %v, SrvProtocols: %v, Timeout: %v}, "+
-			"fileConfig: { Servers: %v, Query: %q, LogLevel: %s, "+
+			"QueryTypes: %v, SrvProtocols: %v, Timeout: %v, "+
+			"Transport: %s, TLSServerNames: %v, TLSCAFile: %q, "+
+			"TLSInsecureSkipVerify: %v, DoHMethod: %s, "+
+			"BootstrapServer: %q, QueryFile: %q, MaxConcurrency: %d, "+
+			"MaxPerServer: %d, RateLimit: %v, ServerRateLimits: %v, "+
+			"Retries: %d, RetryBackoff: %d, RetryJitter: %d, "+
+			"ShowFlags: %v, ShowSections: %v, OutputTemplate: %q, "+
+			"Expect: %v, WaitForConsistency: %v, PollInterval: %d, "+
+			"WaitDeadline: %d, Watch: %d, PropagationETA: %v}, "+
+			"fileConfig: { Servers: %v, Query: %q, Queries: %v, LogLevel: %s, "+
 			"LogFormat: %s, ResultsOutput: %s, DNSErrorsFatal: %v, "+
 			"OmitTimestamp: %v, QueryTypes: %v, SrvProtocols: %v, "+
-			"Timeout: %v}, "+
+			"Timeout: %v, Transport: %s, TLSServerNames: %v, "+
+			"TLSCAFile: %q, TLSInsecureSkipVerify: %v, DoHMethod: %s, "+
+			"BootstrapServer: %q, QueryFile: %q, MaxConcurrency: %d, "+
+			"MaxPerServer: %d, RateLimit: %v, ServerRateLimits: %v, "+
+			"Retries: %d, RetryBackoff: %d, RetryJitter: %d, "+
+			"ShowFlags: %v, ShowSections: %v, OutputTemplate: %q, "+
+			"Expect: %v, WaitForConsistency: %v, PollInterval: %d, "+
+			"WaitDeadline: %d, Watch: %d, PropagationETA: %v}, "+
 			"ConfigFile: %q, ShowVersion: %t,",
 		c.cliConfig.Servers,
-		c.cliConfig.Query,
+		c.cliConfig.Queries,
 		c.cliConfig.LogLevel,
 		c.cliConfig.LogFormat,
 		c.cliConfig.ResultsOutput,
@@ -287,8 +475,32 @@ func (c Config) String() string {
 		c.cliConfig.QueryTypes,
 		c.cliConfig.SrvProtocols,
 		c.cliConfig.Timeout,
+		c.cliConfig.Transport,
+		c.cliConfig.TLSServerNames,
+		c.cliConfig.TLSCAFile,
+		c.cliConfig.TLSInsecureSkipVerify,
+		c.cliConfig.DoHMethod,
+		c.cliConfig.BootstrapServer,
+		c.cliConfig.QueryFile,
+		c.cliConfig.MaxConcurrency,
+		c.cliConfig.MaxPerServer,
+		c.cliConfig.RateLimit,
+		c.cliConfig.ServerRateLimits,
+		c.cliConfig.Retries,
+		c.cliConfig.RetryBackoff,
+		c.cliConfig.RetryJitter,
+		c.cliConfig.ShowFlags,
+		c.cliConfig.ShowSections,
+		c.cliConfig.OutputTemplate,
+		c.cliConfig.Expect.String(),
+		c.cliConfig.WaitForConsistency,
+		c.cliConfig.PollInterval,
+		c.cliConfig.WaitDeadline,
+		c.cliConfig.Watch,
+		c.cliConfig.PropagationETA,
 		c.fileConfig.Servers,
 		c.fileConfig.Query,
+		c.fileConfig.Queries,
 		c.fileConfig.LogLevel,
 		c.fileConfig.LogFormat,
 		c.fileConfig.ResultsOutput,
@@ -297,6 +509,29 @@ func (c Config) String() string {
 		c.fileConfig.QueryTypes,
 		c.fileConfig.SrvProtocols,
 		c.fileConfig.Timeout,
+		c.fileConfig.Transport,
+		c.fileConfig.TLSServerNames,
+		c.fileConfig.TLSCAFile,
+		c.fileConfig.TLSInsecureSkipVerify,
+		c.fileConfig.DoHMethod,
+		c.fileConfig.BootstrapServer,
+		c.fileConfig.QueryFile,
+		c.fileConfig.MaxConcurrency,
+		c.fileConfig.MaxPerServer,
+		c.fileConfig.RateLimit,
+		c.fileConfig.ServerRateLimits,
+		c.fileConfig.Retries,
+		c.fileConfig.RetryBackoff,
+		c.fileConfig.RetryJitter,
+		c.fileConfig.ShowFlags,
+		c.fileConfig.ShowSections,
+		c.fileConfig.OutputTemplate,
+		c.fileConfig.Expect.String(),
+		c.fileConfig.WaitForConsistency,
+		c.fileConfig.PollInterval,
+		c.fileConfig.WaitDeadline,
+		c.fileConfig.Watch,
+		c.fileConfig.PropagationETA,
 		c.configFile,
 		c.showVersion,
 	)
@@ -343,19 +578,38 @@ func (c Config) configureLogging() {
 
 	switch c.LogFormat() {
 	case LogFormatText:
-		log.SetHandler(text.New(os.Stdout))
+		log.SetHandler(text.New(c.logOutput()))
 	case LogFormatCLI:
-		log.SetHandler(cli.New(os.Stdout))
+		log.SetHandler(cli.New(c.logOutput()))
 	case LogFormatLogFmt:
-		log.SetHandler(logfmt.New(os.Stdout))
+		log.SetHandler(logfmt.New(c.logOutput()))
 	case LogFormatJSON:
-		log.SetHandler(json.New(os.Stdout))
+		log.SetHandler(json.New(c.logOutput()))
 	case LogFormatDiscard:
 		log.SetHandler(discard.New())
 	}
 
 }
 
+// logOutput returns the destination for log messages. Log messages are
+// written to stderr when using a machine-readable results output format or an
+// output template so that the results written to stdout may be processed by
+// other tools.
+func (c Config) logOutput() io.Writer {
+
+	if c.OutputTemplate() != "" {
+		return os.Stderr
+	}
+
+	switch c.ResultsOutput() {
+	case ResultsOutputJSON, ResultsOutputCSV, ResultsOutputTSV,
+		ResultsOutputMarkdown, ResultsOutputHTML:
+		return os.Stderr
+	default:
+		return os.Stdout
+	}
+}
+
 // PathExists confirms that the specified path exists
 func PathExists(path string) bool {
 
@@ -489,7 +743,7 @@ func NewConfig() (*Config, error) {
 
 		switch {
 		case config.cliConfig.QueryTypes != nil:
-			err := config.cliConfig.QueryTypes.Set(strings.ToLower(RequestTypeSRV))
+			err := config.cliConfig.QueryTypes.Set(strings.ToLower(dqrs.RequestTypeSRV))
 			if err != nil {
 				return nil, fmt.Errorf(
 					"failed to assert SRV record query type for specified SRV protocols: %w",
@@ -497,7 +751,7 @@ func NewConfig() (*Config, error) {
 				)
 			}
 		case config.fileConfig.QueryTypes != nil:
-			err := config.fileConfig.QueryTypes.Set(strings.ToLower(RequestTypeSRV))
+			err := config.fileConfig.QueryTypes.Set(strings.ToLower(dqrs.RequestTypeSRV))
 			if err != nil {
 				return nil, fmt.Errorf(
 					"failed to assert SRV record query type for specified SRV protocols: %w",
@@ -508,6 +762,13 @@ func NewConfig() (*Config, error) {
 
 	}
 
+	if config.QueryFile() != "" {
+		if err := config.loadQueryFile(config.QueryFile()); err != nil {
+			return nil, fmt.Errorf("failed to load queries: %w", err)
+		}
+		log.Debugf("Loaded %d queries from query file", len(config.queryFileRequests))
+	}
+
 	log.Debug("Validating configuration ...")
 	if err := config.Validate(); err != nil {
 		flag.Usage()
@@ -0,0 +1,72 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expectFlag is the collection of expected values keyed by record type. This
+// is populated by repeated TYPE=VALUE flags or from a table of record types
+// and lists of values in the configuration file.
+type expectFlag map[string][]string
+
+// String returns a comma separated string consisting of all TYPE=VALUE
+// pairs.
+func (ef *expectFlag) String() string {
+
+	// From the `flag` package docs:
+	// "The flag package may call the String method with a zero-valued
+	// receiver, such as a nil pointer."
+	if ef == nil {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(*ef))
+	for recordType, values := range *ef {
+		for _, value := range values {
+			pairs = append(pairs, recordType+"="+value)
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set is called once by the flag package, in command line order, for each
+// flag present. The first equals sign is used to separate the record type
+// from the value so that values containing an equals sign (e.g., TXT
+// records) are supported.
+func (ef *expectFlag) Set(value string) error {
+
+	recordType, expected, found := strings.Cut(value, "=")
+	recordType = strings.ToUpper(strings.TrimSpace(recordType))
+	expected = strings.TrimSpace(expected)
+
+	if !found || recordType == "" || expected == "" {
+		return fmt.Errorf(
+			"invalid option %q provided for expected value; expected TYPE=VALUE",
+			value,
+		)
+	}
+
+	if *ef == nil {
+		*ef = make(expectFlag)
+	}
+
+	for _, existing := range (*ef)[recordType] {
+		if existing == expected {
+			return nil
+		}
+	}
+	(*ef)[recordType] = append((*ef)[recordType], expected)
+
+	return nil
+}
@@ -40,8 +40,8 @@ func (c *Config) handleFlagsConfig() {
 	flag.BoolVar(&c.cliConfig.OmitTimestamp, "omit-timestamp", defaultOmitTimestamp, omitTimestampFlagHelp)
 	flag.BoolVar(&c.cliConfig.OmitTimestamp, "ot", defaultOmitTimestamp, omitTimestampFlagHelp+shorthandFlagSuffix)
 
-	flag.StringVar(&c.cliConfig.Query, "query", defaultQuery, queryFlagHelp)
-	flag.StringVar(&c.cliConfig.Query, "q", defaultQuery, queryFlagHelp+shorthandFlagSuffix)
+	flag.Var(&c.cliConfig.Queries, "query", queryFlagHelp)
+	flag.Var(&c.cliConfig.Queries, "q", queryFlagHelp+shorthandFlagSuffix)
 
 	flag.Var(&c.cliConfig.SrvProtocols, "srv-protocol", srvProtocolFlagHelp)
 	flag.Var(&c.cliConfig.SrvProtocols, "sp", srvProtocolFlagHelp+shorthandFlagSuffix)
@@ -55,6 +55,75 @@ func (c *Config) handleFlagsConfig() {
 	flag.StringVar(&c.cliConfig.ResultsOutput, "ro", defaultResultsOutput, resultsOutputFlagHelp+shorthandFlagSuffix)
 	flag.StringVar(&c.cliConfig.ResultsOutput, "results-output", defaultResultsOutput, resultsOutputFlagHelp)
 
+	flag.StringVar(&c.cliConfig.Transport, "tr", defaultTransport, transportFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.Transport, "transport", defaultTransport, transportFlagHelp)
+
+	flag.Var(&c.cliConfig.TLSServerNames, "tsn", tlsServerNameFlagHelp+shorthandFlagSuffix)
+	flag.Var(&c.cliConfig.TLSServerNames, "tls-server-name", tlsServerNameFlagHelp)
+
+	flag.StringVar(&c.cliConfig.TLSCAFile, "tca", defaultTLSCAFile, tlsCAFileFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.TLSCAFile, "tls-ca-file", defaultTLSCAFile, tlsCAFileFlagHelp)
+
+	flag.BoolVar(&c.cliConfig.TLSInsecureSkipVerify, "tisv", defaultTLSInsecure, tlsInsecureFlagHelp+shorthandFlagSuffix)
+	flag.BoolVar(&c.cliConfig.TLSInsecureSkipVerify, "tls-insecure-skip-verify", defaultTLSInsecure, tlsInsecureFlagHelp)
+
+	flag.StringVar(&c.cliConfig.DoHMethod, "dm", defaultDoHMethod, dohMethodFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.DoHMethod, "doh-method", defaultDoHMethod, dohMethodFlagHelp)
+
+	flag.StringVar(&c.cliConfig.BootstrapServer, "bs", defaultBootstrapServer, bootstrapServerFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.BootstrapServer, "bootstrap-server", defaultBootstrapServer, bootstrapServerFlagHelp)
+
+	flag.StringVar(&c.cliConfig.QueryFile, "qf", defaultQueryFile, queryFileFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.QueryFile, "query-file", defaultQueryFile, queryFileFlagHelp)
+
+	flag.IntVar(&c.cliConfig.MaxConcurrency, "mc", defaultMaxConcurrency, maxConcurrencyFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.MaxConcurrency, "max-concurrency", defaultMaxConcurrency, maxConcurrencyFlagHelp)
+
+	flag.IntVar(&c.cliConfig.MaxPerServer, "mps", defaultMaxPerServer, maxPerServerFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.MaxPerServer, "max-per-server", defaultMaxPerServer, maxPerServerFlagHelp)
+
+	flag.Float64Var(&c.cliConfig.RateLimit, "rl", defaultRateLimit, rateLimitFlagHelp+shorthandFlagSuffix)
+	flag.Float64Var(&c.cliConfig.RateLimit, "rate-limit", defaultRateLimit, rateLimitFlagHelp)
+
+	flag.Var(&c.cliConfig.ServerRateLimits, "srl", serverRateLimitFlagHelp+shorthandFlagSuffix)
+	flag.Var(&c.cliConfig.ServerRateLimits, "server-rate-limit", serverRateLimitFlagHelp)
+
+	flag.IntVar(&c.cliConfig.Retries, "r", defaultRetries, retriesFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.Retries, "retries", defaultRetries, retriesFlagHelp)
+
+	flag.IntVar(&c.cliConfig.RetryBackoff, "rb", defaultRetryBackoff, retryBackoffFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.RetryBackoff, "retry-backoff", defaultRetryBackoff, retryBackoffFlagHelp)
+
+	flag.IntVar(&c.cliConfig.RetryJitter, "rj", defaultRetryJitter, retryJitterFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.RetryJitter, "retry-jitter", defaultRetryJitter, retryJitterFlagHelp)
+
+	flag.BoolVar(&c.cliConfig.ShowFlags, "sf", defaultShowFlags, showFlagsFlagHelp+shorthandFlagSuffix)
+	flag.BoolVar(&c.cliConfig.ShowFlags, "show-flags", defaultShowFlags, showFlagsFlagHelp)
+
+	flag.BoolVar(&c.cliConfig.ShowSections, "ss", defaultShowSections, showSectionsFlagHelp+shorthandFlagSuffix)
+	flag.BoolVar(&c.cliConfig.ShowSections, "show-sections", defaultShowSections, showSectionsFlagHelp)
+
+	flag.BoolVar(&c.cliConfig.WaitForConsistency, "wfc", defaultWaitForConsistency, waitFlagHelp+shorthandFlagSuffix)
+	flag.BoolVar(&c.cliConfig.WaitForConsistency, "wait-for-consistency", defaultWaitForConsistency, waitFlagHelp)
+
+	flag.IntVar(&c.cliConfig.PollInterval, "pi", defaultPollInterval, pollIntervalFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.PollInterval, "poll-interval", defaultPollInterval, pollIntervalFlagHelp)
+
+	flag.IntVar(&c.cliConfig.WaitDeadline, "wd", defaultWaitDeadline, waitDeadlineFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.WaitDeadline, "wait-deadline", defaultWaitDeadline, waitDeadlineFlagHelp)
+
+	flag.IntVar(&c.cliConfig.Watch, "w", defaultWatch, watchFlagHelp+shorthandFlagSuffix)
+	flag.IntVar(&c.cliConfig.Watch, "watch", defaultWatch, watchFlagHelp)
+
+	flag.BoolVar(&c.cliConfig.PropagationETA, "pe", defaultPropagationETA, propagationETAFlagHelp+shorthandFlagSuffix)
+	flag.BoolVar(&c.cliConfig.PropagationETA, "propagation-eta", defaultPropagationETA, propagationETAFlagHelp)
+
+	flag.Var(&c.cliConfig.Expect, "e", expectFlagHelp+shorthandFlagSuffix)
+	flag.Var(&c.cliConfig.Expect, "expect", expectFlagHelp)
+
+	flag.StringVar(&c.cliConfig.OutputTemplate, "tmpl", defaultOutputTemplate, outputTemplateFlagHelp+shorthandFlagSuffix)
+	flag.StringVar(&c.cliConfig.OutputTemplate, "output-template", defaultOutputTemplate, outputTemplateFlagHelp)
+
 	flag.Usage = flagsUsage()
 	flag.Parse()
 }
@@ -8,15 +8,39 @@
 package config
 
 import (
+	"strings"
 	"time"
 
 	"github.com/apex/log"
 )
 
-// Servers returns a slice of configured DNS server entries or nil if DNS
+// Servers returns a slice of parsed DNS server specifications or nil if DNS
 // server entries were not provided. CLI flag values take precedence if
+// provided. Invalid entries are reported by Validate and are omitted here.
+func (c Config) Servers() []Server {
+
+	entries := c.serverEntries()
+	if entries == nil {
+		return nil
+	}
+
+	servers := make([]Server, 0, len(entries))
+	for _, entry := range entries {
+		server, err := ParseServer(entry)
+		if err != nil {
+			log.Debugf("Skipping invalid DNS server entry %q: %v", entry, err)
+			continue
+		}
+		servers = append(servers, server)
+	}
+
+	return servers
+}
+
+// serverEntries returns the user-provided DNS server entries as-is or nil if
+// DNS server entries were not provided. CLI flag values take precedence if
 // provided.
-func (c Config) Servers() []string {
+func (c Config) serverEntries() []string {
 
 	switch {
 	case c.cliConfig.Servers != nil:
@@ -28,19 +52,68 @@ func (c Config) Servers() []string {
 	}
 }
 
-// Query returns the user-provided DNS server query or the default value if
-// DNS server query was not provided. CLI flag values take precedence if
-// provided.
-func (c Config) Query() string {
+// Queries returns the user-provided DNS server queries or nil if DNS server
+// queries were not provided. CLI flag values take precedence if provided. For
+// config file settings, the single query setting is combined with the list
+// of queries.
+func (c Config) Queries() []string {
+
+	switch {
+	case c.cliConfig.Queries != nil:
+		return c.cliConfig.Queries
+	case c.fileConfig.Queries != nil || c.fileConfig.Query != "":
+		queries := make(multiValueFlag, 0, len(c.fileConfig.Queries)+1)
+		if c.fileConfig.Query != "" {
+			_ = queries.Set(c.fileConfig.Query)
+		}
+		for _, query := range c.fileConfig.Queries {
+			_ = queries.Set(query)
+		}
+		return queries
+	default:
+		return nil
+	}
+}
+
+// QueryFile returns the user-provided path to a file containing queries or
+// an empty string if not provided. A value of "-" indicates that queries are
+// read from standard input. CLI flag values take precedence if provided.
+func (c Config) QueryFile() string {
 
 	switch {
-	case c.cliConfig.Query != "":
-		return c.cliConfig.Query
-	case c.fileConfig.Query != "":
-		return c.fileConfig.Query
+	case c.cliConfig.QueryFile != "":
+		return c.cliConfig.QueryFile
+	case c.fileConfig.QueryFile != "":
+		return c.fileConfig.QueryFile
 	default:
-		return defaultQuery
+		return defaultQueryFile
+	}
+}
+
+// QueryRequests returns the complete set of query requests to submit to each
+// DNS server. Queries provided via flag or config file are requested using
+// the configured query types. Queries loaded from a query file are requested
+// using the record types specified on the same line, or the configured query
+// types if none were specified.
+func (c Config) QueryRequests() []QueryRequest {
+
+	requests := make([]QueryRequest, 0, len(c.Queries())+len(c.queryFileRequests))
+
+	for _, query := range c.Queries() {
+		requests = append(requests, QueryRequest{
+			Name:  query,
+			Types: c.QueryTypes(),
+		})
+	}
+
+	for _, request := range c.queryFileRequests {
+		if len(request.Types) == 0 {
+			request.Types = c.QueryTypes()
+		}
+		requests = append(requests, request)
 	}
+
+	return requests
 }
 
 // LogLevel returns the user-provided logging level or the default value if
@@ -86,6 +159,119 @@ func (c Config) ResultsOutput() string {
 	}
 }
 
+// Transport returns the user-provided choice of which network transport to
+// use when submitting DNS queries or the default value if not provided. CLI
+// flag values take precedence if provided.
+func (c Config) Transport() string {
+
+	switch {
+	case c.cliConfig.Transport != defaultTransport:
+		return c.cliConfig.Transport
+	case c.fileConfig.Transport != "":
+		return c.fileConfig.Transport
+	default:
+		return defaultTransport
+	}
+}
+
+// TLSServerNames returns the user-provided list of SERVER=NAME pairs used to
+// verify the certificates presented by DNS servers or nil if not provided.
+// CLI flag values take precedence if provided.
+func (c Config) TLSServerNames() []string {
+
+	switch {
+	case c.cliConfig.TLSServerNames != nil:
+		return c.cliConfig.TLSServerNames
+	case c.fileConfig.TLSServerNames != nil:
+		return c.fileConfig.TLSServerNames
+	default:
+		return nil
+	}
+}
+
+// TLSServerName returns the name used to verify the certificate presented by
+// the specified DNS server or an empty string if a name was not provided for
+// the server. Names may be specified using either the DNS server entry as
+// provided or just the host portion of the entry. The original hostname is
+// returned for servers resolved from a hostname if a name was not provided.
+func (c Config) TLSServerName(server Server) string {
+
+	for _, entry := range c.TLSServerNames() {
+		entryServer, name, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+
+		entryServer = strings.TrimSpace(entryServer)
+		if entryServer == server.Spec || entryServer == server.Host ||
+			(server.Hostname != "" && entryServer == server.Hostname) {
+			return strings.TrimSpace(name)
+		}
+	}
+
+	return server.Hostname
+}
+
+// BootstrapServer returns the user-provided DNS server used to resolve DNS
+// server entries specified by hostname or an empty string if not provided.
+// If not provided, the system resolver is used. CLI flag values take
+// precedence if provided.
+func (c Config) BootstrapServer() string {
+
+	switch {
+	case c.cliConfig.BootstrapServer != "":
+		return c.cliConfig.BootstrapServer
+	case c.fileConfig.BootstrapServer != "":
+		return c.fileConfig.BootstrapServer
+	default:
+		return defaultBootstrapServer
+	}
+}
+
+// TLSCAFile returns the user-provided path to a CA bundle used to verify DNS
+// server certificates or an empty string if not provided. CLI flag values
+// take precedence if provided.
+func (c Config) TLSCAFile() string {
+
+	switch {
+	case c.cliConfig.TLSCAFile != "":
+		return c.cliConfig.TLSCAFile
+	case c.fileConfig.TLSCAFile != "":
+		return c.fileConfig.TLSCAFile
+	default:
+		return defaultTLSCAFile
+	}
+}
+
+// TLSInsecureSkipVerify returns the user-provided choice of whether
+// certificate verification is skipped when using an encrypted transport or
+// the default value for this choice.
+func (c Config) TLSInsecureSkipVerify() bool {
+	switch {
+	case c.cliConfig.TLSInsecureSkipVerify:
+		return c.cliConfig.TLSInsecureSkipVerify
+	case c.fileConfig.TLSInsecureSkipVerify:
+		return c.fileConfig.TLSInsecureSkipVerify
+	default:
+		return defaultTLSInsecure
+	}
+}
+
+// DoHMethod returns the user-provided choice of which HTTP method to use when
+// submitting DNS-over-HTTPS (DoH) queries or the default value if not
+// provided. CLI flag values take precedence if provided.
+func (c Config) DoHMethod() string {
+
+	switch {
+	case c.cliConfig.DoHMethod != defaultDoHMethod:
+		return strings.ToLower(c.cliConfig.DoHMethod)
+	case c.fileConfig.DoHMethod != "":
+		return strings.ToLower(c.fileConfig.DoHMethod)
+	default:
+		return defaultDoHMethod
+	}
+}
+
 // ShowVersion returns the user-provided choice of displaying the application
 // version and exiting or the default value for this choice.
 func (c Config) ShowVersion() bool {
@@ -139,7 +325,9 @@ func (c Config) Timeout() time.Duration {
 		log.Debugf("Timeout value specified via CLI flag: %d", c.cliConfig.Timeout)
 		log.Debugf("Calculated timeout value: %v", duration)
 		return duration
-	case c.fileConfig.Timeout != defaultTimeout:
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.Timeout != 0 && c.fileConfig.Timeout != defaultTimeout:
 		duration := time.Duration(c.fileConfig.Timeout) * time.Second
 		log.Debugf("Timeout value specified via config file: %d", c.fileConfig.Timeout)
 		log.Debugf("Calculated timeout value: %v", duration)
@@ -178,3 +366,265 @@ func (c Config) OmitTimestamp() bool {
 		return defaultOmitTimestamp
 	}
 }
+
+// ShowFlags returns the user-provided choice of whether the header flags set
+// in each response are included in the results output or the default value
+// if not provided.
+func (c Config) ShowFlags() bool {
+	switch {
+	case c.cliConfig.ShowFlags:
+		return c.cliConfig.ShowFlags
+	case c.fileConfig.ShowFlags:
+		return c.fileConfig.ShowFlags
+	default:
+		return defaultShowFlags
+	}
+}
+
+// ShowSections returns the user-provided choice of whether records from the
+// authority and additional sections of each response are included in the
+// results output or the default value if not provided.
+func (c Config) ShowSections() bool {
+	switch {
+	case c.cliConfig.ShowSections:
+		return c.cliConfig.ShowSections
+	case c.fileConfig.ShowSections:
+		return c.fileConfig.ShowSections
+	default:
+		return defaultShowSections
+	}
+}
+
+// MaxConcurrency returns the user-provided maximum number of DNS queries
+// in-flight at any one time across all DNS servers or the default value if
+// not provided.
+func (c Config) MaxConcurrency() int {
+	switch {
+	case c.cliConfig.MaxConcurrency != defaultMaxConcurrency:
+		return c.cliConfig.MaxConcurrency
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.MaxConcurrency != 0:
+		return c.fileConfig.MaxConcurrency
+	default:
+		return defaultMaxConcurrency
+	}
+}
+
+// MaxPerServer returns the user-provided maximum number of DNS queries
+// in-flight to any single DNS server at any one time or the default value if
+// not provided.
+func (c Config) MaxPerServer() int {
+	switch {
+	case c.cliConfig.MaxPerServer != defaultMaxPerServer:
+		return c.cliConfig.MaxPerServer
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.MaxPerServer != 0:
+		return c.fileConfig.MaxPerServer
+	default:
+		return defaultMaxPerServer
+	}
+}
+
+// RateLimit returns the user-provided maximum number of DNS queries per
+// second submitted across all DNS servers or the default value if not
+// provided. A value of zero indicates that no limit is applied.
+func (c Config) RateLimit() float64 {
+	switch {
+	case c.cliConfig.RateLimit != defaultRateLimit:
+		return c.cliConfig.RateLimit
+	case c.fileConfig.RateLimit != 0:
+		return c.fileConfig.RateLimit
+	default:
+		return defaultRateLimit
+	}
+}
+
+// ServerRateLimits returns the user-provided list of SERVER=QPS pairs used to
+// limit the rate of queries submitted to specific DNS servers or nil if not
+// provided. CLI flag values take precedence if provided.
+func (c Config) ServerRateLimits() []string {
+
+	switch {
+	case c.cliConfig.ServerRateLimits != nil:
+		return c.cliConfig.ServerRateLimits
+	case c.fileConfig.ServerRateLimits != nil:
+		return c.fileConfig.ServerRateLimits
+	default:
+		return nil
+	}
+}
+
+// ServerRateLimit returns the maximum number of DNS queries per second
+// submitted to the specified DNS server or zero if a limit was not provided
+// for the server. Limits may be specified using either the DNS server entry
+// as provided, just the host portion of the entry or the original hostname
+// for servers resolved from a hostname.
+func (c Config) ServerRateLimit(server Server) float64 {
+
+	for _, entry := range c.ServerRateLimits() {
+		limit, err := parseServerRateLimit(entry)
+		if err != nil {
+			continue
+		}
+
+		if limit.server == server.Spec || limit.server == server.Host ||
+			(server.Hostname != "" && limit.server == server.Hostname) {
+			return limit.queriesPerSecond
+		}
+	}
+
+	return 0
+}
+
+// Retries returns the user-provided number of times a failed DNS query is
+// resubmitted or the default value if not provided.
+func (c Config) Retries() int {
+	switch {
+	case c.cliConfig.Retries != defaultRetries:
+		return c.cliConfig.Retries
+	case c.fileConfig.Retries != 0:
+		return c.fileConfig.Retries
+	default:
+		return defaultRetries
+	}
+}
+
+// RetryBackoff returns the user-provided delay before the first retry of a
+// failed DNS query or the default value if not provided.
+func (c Config) RetryBackoff() time.Duration {
+	switch {
+	case c.cliConfig.RetryBackoff != defaultRetryBackoff:
+		return time.Duration(c.cliConfig.RetryBackoff) * time.Millisecond
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.RetryBackoff != 0:
+		return time.Duration(c.fileConfig.RetryBackoff) * time.Millisecond
+	default:
+		return time.Duration(defaultRetryBackoff) * time.Millisecond
+	}
+}
+
+// RetryJitter returns the user-provided maximum random delay added to each
+// retry of a failed DNS query or the default value if not provided.
+func (c Config) RetryJitter() time.Duration {
+	switch {
+	case c.cliConfig.RetryJitter != defaultRetryJitter:
+		return time.Duration(c.cliConfig.RetryJitter) * time.Millisecond
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.RetryJitter != 0:
+		return time.Duration(c.fileConfig.RetryJitter) * time.Millisecond
+	default:
+		return time.Duration(defaultRetryJitter) * time.Millisecond
+	}
+}
+
+// OutputTemplate returns the user-provided Go text/template used to generate
+// the results output or the default value if not provided. Templates read
+// from a file are specified as @ followed by the path to the file.
+func (c Config) OutputTemplate() string {
+	switch {
+	case c.cliConfig.OutputTemplate != "":
+		return c.cliConfig.OutputTemplate
+	case c.fileConfig.OutputTemplate != "":
+		return c.fileConfig.OutputTemplate
+	default:
+		return defaultOutputTemplate
+	}
+}
+
+// Expect returns the user-provided collection of expected values keyed by
+// record type or nil if not provided. CLI flag values take precedence if
+// provided.
+func (c Config) Expect() map[string][]string {
+
+	switch {
+	case len(c.cliConfig.Expect) > 0:
+		return c.cliConfig.Expect
+	case len(c.fileConfig.Expect) > 0:
+		// Normalize record types provided in the configuration file to match
+		// those provided via CLI flag.
+		expect := make(map[string][]string, len(c.fileConfig.Expect))
+		for recordType, values := range c.fileConfig.Expect {
+			key := strings.ToUpper(strings.TrimSpace(recordType))
+			expect[key] = append(expect[key], values...)
+		}
+		return expect
+	default:
+		return nil
+	}
+}
+
+// WaitForConsistency returns the user-provided choice of whether DNS queries
+// are resubmitted until all DNS servers have converged or the default value
+// if not provided.
+func (c Config) WaitForConsistency() bool {
+	switch {
+	case c.cliConfig.WaitForConsistency:
+		return c.cliConfig.WaitForConsistency
+	case c.fileConfig.WaitForConsistency:
+		return c.fileConfig.WaitForConsistency
+	default:
+		return defaultWaitForConsistency
+	}
+}
+
+// PollInterval returns the user-provided delay between each poll of DNS
+// servers which have not yet converged or the default value if not provided.
+func (c Config) PollInterval() time.Duration {
+	switch {
+	case c.cliConfig.PollInterval != defaultPollInterval:
+		return time.Duration(c.cliConfig.PollInterval) * time.Second
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.PollInterval != 0:
+		return time.Duration(c.fileConfig.PollInterval) * time.Second
+	default:
+		return time.Duration(defaultPollInterval) * time.Second
+	}
+}
+
+// WaitDeadline returns the user-provided maximum amount of time to wait for
+// all DNS servers to converge or the default value if not provided.
+func (c Config) WaitDeadline() time.Duration {
+	switch {
+	case c.cliConfig.WaitDeadline != defaultWaitDeadline:
+		return time.Duration(c.cliConfig.WaitDeadline) * time.Second
+	// A zero value indicates that the setting was not provided via config
+	// file.
+	case c.fileConfig.WaitDeadline != 0:
+		return time.Duration(c.fileConfig.WaitDeadline) * time.Second
+	default:
+		return time.Duration(defaultWaitDeadline) * time.Second
+	}
+}
+
+// Watch returns the user-provided delay between each run of the configured
+// queries when watching for changes or the default value if not provided. A
+// zero value indicates that watch mode is disabled.
+func (c Config) Watch() time.Duration {
+	switch {
+	case c.cliConfig.Watch != defaultWatch:
+		return time.Duration(c.cliConfig.Watch) * time.Second
+	case c.fileConfig.Watch != defaultWatch:
+		return time.Duration(c.fileConfig.Watch) * time.Second
+	default:
+		return time.Duration(defaultWatch) * time.Second
+	}
+}
+
+// PropagationETA returns the user-provided choice of whether the expected
+// refresh time is estimated for each DNS server which provided an outdated
+// answer or the default value if not provided.
+func (c Config) PropagationETA() bool {
+	switch {
+	case c.cliConfig.PropagationETA:
+		return c.cliConfig.PropagationETA
+	case c.fileConfig.PropagationETA:
+		return c.fileConfig.PropagationETA
+	default:
+		return defaultPropagationETA
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// queryFileStdin is the query file name used to indicate that queries should
+// be read from standard input.
+const queryFileStdin string = "-"
+
+// queryFileCommentPrefix marks the start of a comment in a query file.
+// Comments may be placed on their own line or at the end of a line.
+const queryFileCommentPrefix string = "#"
+
+// QueryRequest is a query name along with the DNS record types requested for
+// it.
+type QueryRequest struct {
+
+	// Name is the FQDN query string submitted to each DNS server.
+	Name string
+
+	// Types is the list of DNS record types requested for the query name.
+	Types []string
+}
+
+// ParseQueryFile reads query requests from the given io.Reader. Each line
+// specifies a query name optionally followed by one or more record types
+// separated by whitespace (e.g., "www.example.com AAAA"). Blank lines and
+// comments are ignored. If record types are not specified for a query name,
+// Types is left empty so that the globally configured query types may be
+// applied.
+func ParseQueryFile(r io.Reader) ([]QueryRequest, error) {
+
+	var requests []QueryRequest
+
+	scanner := bufio.NewScanner(r)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+
+		line := scanner.Text()
+		if i := strings.Index(line, queryFileCommentPrefix); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		requests = append(requests, QueryRequest{
+			Name:  fields[0],
+			Types: fields[1:],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query file at line %d: %w", lineNum, err)
+	}
+
+	return requests, nil
+}
+
+// loadQueryFile reads query requests from the user-specified query file or
+// from standard input if requested.
+func (c *Config) loadQueryFile(queryFile string) error {
+
+	if queryFile == queryFileStdin {
+		log.Debug("Reading queries from standard input")
+
+		requests, err := ParseQueryFile(os.Stdin)
+		if err != nil {
+			return err
+		}
+		c.queryFileRequests = requests
+
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"query_file": queryFile,
+	}).Debug("Attempting to open query file")
+
+	fh, err := os.Open(filepath.Clean(queryFile))
+	if err != nil {
+		return fmt.Errorf("failed to open query file: %w", err)
+	}
+
+	defer func() {
+		if err := fh.Close(); err != nil {
+			// Ignore "file already closed" errors
+			if !errors.Is(err, os.ErrClosed) {
+				log.Errorf(
+					"loadQueryFile: failed to close file %q: %s",
+					queryFile,
+					err.Error(),
+				)
+			}
+		}
+	}()
+
+	requests, err := ParseQueryFile(fh)
+	if err != nil {
+		return err
+	}
+	c.queryFileRequests = requests
+
+	return nil
+}
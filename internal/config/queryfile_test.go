@@ -0,0 +1,88 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQueryFile(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  []QueryRequest
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "name only",
+			input: "www.example.com\n",
+			want: []QueryRequest{
+				{Name: "www.example.com", Types: []string{}},
+			},
+		},
+		{
+			name:  "name with types",
+			input: "www.example.com A AAAA\nexample.com\tMX",
+			want: []QueryRequest{
+				{Name: "www.example.com", Types: []string{"A", "AAAA"}},
+				{Name: "example.com", Types: []string{"MX"}},
+			},
+		},
+		{
+			name: "blank lines and comments",
+			input: strings.Join([]string{
+				"# Hosts to check",
+				"",
+				"   ",
+				"www.example.com AAAA # trailing comment",
+				"  mail.example.com  ",
+				"#disabled.example.com",
+			}, "\n"),
+			want: []QueryRequest{
+				{Name: "www.example.com", Types: []string{"AAAA"}},
+				{Name: "mail.example.com", Types: []string{}},
+			},
+		},
+		{
+			name:  "windows line endings",
+			input: "www.example.com A\r\nexample.com\r\n",
+			want: []QueryRequest{
+				{Name: "www.example.com", Types: []string{"A"}},
+				{Name: "example.com", Types: []string{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQueryFile(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryFileLineTooLong(t *testing.T) {
+
+	input := strings.Repeat("a", 1024*1024) + "\n"
+	if _, err := ParseQueryFile(strings.NewReader(input)); err == nil {
+		t.Fatal("expected error for line exceeding the maximum length, got nil")
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// serverRateLimit is the maximum number of DNS queries per second submitted
+// to a specific DNS server.
+type serverRateLimit struct {
+
+	// server is the DNS server entry, host or hostname the limit applies to.
+	server string
+
+	// queriesPerSecond is the maximum number of DNS queries per second
+	// submitted to the DNS server.
+	queriesPerSecond float64
+}
+
+// parseServerRateLimit parses a SERVER=QPS pair. The last equals sign is used
+// to separate the DNS server from the limit so that DNS server entries
+// containing an equals sign (e.g., DoH URLs with query parameters) are
+// supported.
+func parseServerRateLimit(entry string) (serverRateLimit, error) {
+
+	idx := strings.LastIndex(entry, "=")
+	if idx < 0 {
+		return serverRateLimit{}, fmt.Errorf(
+			"invalid option %q provided for server rate limit; expected SERVER=QPS",
+			entry,
+		)
+	}
+
+	server := strings.TrimSpace(entry[:idx])
+	if server == "" {
+		return serverRateLimit{}, fmt.Errorf(
+			"invalid option %q provided for server rate limit; expected SERVER=QPS",
+			entry,
+		)
+	}
+
+	queriesPerSecond, err := strconv.ParseFloat(strings.TrimSpace(entry[idx+1:]), 64)
+	if err != nil || queriesPerSecond <= 0 {
+		return serverRateLimit{}, fmt.Errorf(
+			"invalid option %q provided for server rate limit; QPS must be a number greater than 0",
+			entry,
+		)
+	}
+
+	return serverRateLimit{
+		server:           server,
+		queriesPerSecond: queriesPerSecond,
+	}, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestParseServerRateLimit(t *testing.T) {
+
+	tests := []struct {
+		entry   string
+		want    serverRateLimit
+		wantErr bool
+	}{
+		{
+			entry: "8.8.8.8=5",
+			want:  serverRateLimit{server: "8.8.8.8", queriesPerSecond: 5},
+		},
+		{
+			entry: " 192.0.2.1:5353 = 0.5 ",
+			want:  serverRateLimit{server: "192.0.2.1:5353", queriesPerSecond: 0.5},
+		},
+		{
+			entry: "udp://[2001:db8::1]:5353=10",
+			want:  serverRateLimit{server: "udp://[2001:db8::1]:5353", queriesPerSecond: 10},
+		},
+		{
+			entry: "https://dns.example/dns-query?ct=1=2",
+			want:  serverRateLimit{server: "https://dns.example/dns-query?ct=1", queriesPerSecond: 2},
+		},
+		{entry: "8.8.8.8", wantErr: true},
+		{entry: "=5", wantErr: true},
+		{entry: "8.8.8.8=", wantErr: true},
+		{entry: "8.8.8.8=fast", wantErr: true},
+		{entry: "8.8.8.8=0", wantErr: true},
+		{entry: "8.8.8.8=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, err := parseServerRateLimit(tt.entry)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
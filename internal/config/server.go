@@ -0,0 +1,231 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// serverSchemeSeparator separates the optional transport scheme from the
+// remainder of a DNS server specification.
+const serverSchemeSeparator string = "://"
+
+// serverLabelSeparator separates the optional friendly label from the
+// remainder of a DNS server specification.
+const serverLabelSeparator string = "#"
+
+// Server is a DNS server specification parsed from a user-provided DNS server
+// entry. Entries are specified using the syntax
+// [transport://]host[:port][#label], where the transport, port and label are
+// optional. IPv6 addresses must be enclosed in square brackets if a port is
+// specified. DNS-over-HTTPS (DoH) servers are specified as https:// URLs.
+//
+// Examples:
+//
+//   - 8.8.8.8
+//   - 8.8.8.8#google
+//   - udp://[2001:db8::1]:5353#lab-resolver
+//   - tls://1.1.1.1:853#cloudflare
+//   - https://dns.example/dns-query#doh
+//   - quic://dns.example:853
+type Server struct {
+
+	// Spec is the original user-provided DNS server entry.
+	Spec string
+
+	// Host is the hostname or IP Address of the DNS server.
+	Host string
+
+	// Port is the port used to submit queries to the DNS server. If empty,
+	// the default port for the transport is used.
+	Port string
+
+	// Transport is the network transport used to submit queries to the DNS
+	// server. If empty, the globally configured transport is used.
+	Transport string
+
+	// Label is an optional friendly name for the DNS server which is shown
+	// in results output instead of the server address.
+	Label string
+
+	// URL is the DNS-over-HTTPS (DoH) endpoint for the DNS server. This is
+	// only set for servers using the https transport.
+	URL string
+
+	// Hostname is the hostname originally specified for the DNS server. This
+	// is only set for servers which were resolved from a hostname to one of
+	// its IP Addresses, in which case Host is the resolved IP Address.
+	Hostname string
+}
+
+// ParseServer parses a user-provided DNS server entry into a Server value. An
+// error is returned if the entry is empty, specifies an unsupported
+// transport or specifies an invalid port.
+func ParseServer(spec string) (Server, error) {
+
+	entry := strings.TrimSpace(spec)
+	if entry == "" {
+		return Server{}, fmt.Errorf("empty DNS server entry provided")
+	}
+
+	server := Server{Spec: spec}
+
+	if i := strings.LastIndex(entry, serverLabelSeparator); i >= 0 {
+		server.Label = strings.TrimSpace(entry[i+len(serverLabelSeparator):])
+		entry = strings.TrimSpace(entry[:i])
+	}
+
+	if scheme, remainder, found := strings.Cut(entry, serverSchemeSeparator); found {
+		server.Transport = strings.ToLower(scheme)
+
+		switch server.Transport {
+		case TransportUDP:
+		case TransportTCP:
+		case TransportAuto:
+		case TransportTLS:
+		case TransportQUIC:
+
+		// DNS-over-HTTPS (DoH) servers are specified as URLs; the path is
+		// significant and is retained as-is.
+		case TransportHTTPS:
+			serverURL, err := url.Parse(server.Transport + serverSchemeSeparator + remainder)
+			if err != nil || serverURL.Hostname() == "" {
+				return Server{}, fmt.Errorf("invalid DNS-over-HTTPS server URL in entry %q", spec)
+			}
+			server.URL = serverURL.String()
+			server.Host = serverURL.Hostname()
+			server.Port = serverURL.Port()
+
+			return server, nil
+
+		default:
+			return Server{}, fmt.Errorf(
+				"unsupported transport %q specified in DNS server entry %q",
+				scheme,
+				spec,
+			)
+		}
+
+		entry = remainder
+	}
+
+	host, port, err := net.SplitHostPort(entry)
+	switch {
+	case err == nil:
+		portNum, err := strconv.Atoi(port)
+		if err != nil || portNum < 1 || portNum > 65535 {
+			return Server{}, fmt.Errorf("invalid port %q specified in DNS server entry %q", port, spec)
+		}
+		server.Host = host
+		server.Port = port
+
+	// No port specified; this is also the case for bare IPv6 addresses.
+	default:
+		server.Host = strings.Trim(entry, "[]")
+	}
+
+	if server.Host == "" || strings.ContainsAny(server.Host, "/[]") {
+		return Server{}, fmt.Errorf("invalid host specified in DNS server entry %q", spec)
+	}
+
+	return server, nil
+}
+
+// Address returns the address used to submit queries to the DNS server. This
+// is the DoH URL for DNS-over-HTTPS servers, a quic:// address for
+// DNS-over-QUIC servers and the host (and port, if specified) for all other
+// servers.
+func (s Server) Address() string {
+
+	var hostPort string
+	switch {
+	case s.Port != "":
+		hostPort = net.JoinHostPort(s.Host, s.Port)
+	case strings.Contains(s.Host, ":"):
+		hostPort = "[" + s.Host + "]"
+	default:
+		hostPort = s.Host
+	}
+
+	switch s.Transport {
+	case TransportHTTPS:
+		return s.URL
+	case TransportQUIC:
+		return TransportQUIC + serverSchemeSeparator + hostPort
+	default:
+		// Bare IPv6 addresses are accepted by the query logic as-is.
+		if s.Port == "" {
+			return s.Host
+		}
+		return hostPort
+	}
+}
+
+// ID returns an identifier which is unique for each DNS server entry. This is
+// the server address along with the transport and label, if specified, so
+// that entries for the same address using different transports or labels
+// are told apart.
+func (s Server) ID() string {
+
+	id := s.Address()
+	switch s.Transport {
+	case "", TransportHTTPS, TransportQUIC:
+		// The address already includes the scheme, if any.
+	default:
+		id = s.Transport + serverSchemeSeparator + id
+	}
+
+	if s.Label != "" {
+		id += serverLabelSeparator + s.Label
+	}
+
+	return id
+}
+
+// Name returns the friendly label for the DNS server if provided, otherwise
+// the server address. If the server was resolved from a hostname, the label
+// (or hostname if a label was not provided) is returned along with the
+// resolved IP Address.
+func (s Server) Name() string {
+
+	name := s.Label
+	if name == "" {
+		name = s.Hostname
+	}
+
+	switch {
+	case name == "":
+		return s.Address()
+	case s.Hostname != "":
+		return fmt.Sprintf("%s (%s)", name, s.Host)
+	default:
+		return name
+	}
+}
+
+// NeedsResolution indicates whether the DNS server is specified by hostname
+// and should be resolved to its IP Addresses before use. DNS-over-HTTPS (DoH)
+// servers are excluded as the hostname is needed to submit requests to the
+// DoH endpoint.
+func (s Server) NeedsResolution() bool {
+	return s.Transport != TransportHTTPS && s.Hostname == "" && net.ParseIP(s.Host) == nil
+}
+
+// Resolved returns a copy of the DNS server which uses the given IP Address
+// in place of the originally specified hostname.
+func (s Server) Resolved(ipAddress string) Server {
+	resolved := s
+	resolved.Hostname = s.Host
+	resolved.Host = ipAddress
+
+	return resolved
+}
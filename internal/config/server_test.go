@@ -0,0 +1,160 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package config
+
+import (
+	"testing"
+)
+
+func TestParseServer(t *testing.T) {
+
+	tests := []struct {
+		spec        string
+		want        Server
+		wantAddress string
+		wantErr     bool
+	}{
+		{
+			spec:        "8.8.8.8",
+			want:        Server{Host: "8.8.8.8"},
+			wantAddress: "8.8.8.8",
+		},
+		{
+			spec:        "8.8.8.8#google",
+			want:        Server{Host: "8.8.8.8", Label: "google"},
+			wantAddress: "8.8.8.8",
+		},
+		{
+			spec:        "192.0.2.1:5353",
+			want:        Server{Host: "192.0.2.1", Port: "5353"},
+			wantAddress: "192.0.2.1:5353",
+		},
+		{
+			spec:        "2001:db8::1",
+			want:        Server{Host: "2001:db8::1"},
+			wantAddress: "2001:db8::1",
+		},
+		{
+			spec:        "[2001:db8::1]",
+			want:        Server{Host: "2001:db8::1"},
+			wantAddress: "2001:db8::1",
+		},
+		{
+			spec:        "udp://[2001:db8::1]:5353#lab-resolver",
+			want:        Server{Host: "2001:db8::1", Port: "5353", Transport: TransportUDP, Label: "lab-resolver"},
+			wantAddress: "[2001:db8::1]:5353",
+		},
+		{
+			spec:        "TCP://192.0.2.1",
+			want:        Server{Host: "192.0.2.1", Transport: TransportTCP},
+			wantAddress: "192.0.2.1",
+		},
+		{
+			spec:        "tls://1.1.1.1:853#cloudflare",
+			want:        Server{Host: "1.1.1.1", Port: "853", Transport: TransportTLS, Label: "cloudflare"},
+			wantAddress: "1.1.1.1:853",
+		},
+		{
+			spec:        "quic://dns.example",
+			want:        Server{Host: "dns.example", Transport: TransportQUIC},
+			wantAddress: "quic://dns.example",
+		},
+		{
+			spec:        "quic://[2001:db8::1]",
+			want:        Server{Host: "2001:db8::1", Transport: TransportQUIC},
+			wantAddress: "quic://[2001:db8::1]",
+		},
+		{
+			spec: "https://dns.example/dns-query?ct=1#doh",
+			want: Server{
+				Host:      "dns.example",
+				Transport: TransportHTTPS,
+				Label:     "doh",
+				URL:       "https://dns.example/dns-query?ct=1",
+			},
+			wantAddress: "https://dns.example/dns-query?ct=1",
+		},
+		{
+			spec: "https://dns.example:8443/dns-query",
+			want: Server{
+				Host:      "dns.example",
+				Port:      "8443",
+				Transport: TransportHTTPS,
+				URL:       "https://dns.example:8443/dns-query",
+			},
+			wantAddress: "https://dns.example:8443/dns-query",
+		},
+		{spec: "", wantErr: true},
+		{spec: "   ", wantErr: true},
+		{spec: "#label", wantErr: true},
+		{spec: "ftp://192.0.2.1", wantErr: true},
+		{spec: "192.0.2.1:0", wantErr: true},
+		{spec: "192.0.2.1:65536", wantErr: true},
+		{spec: "192.0.2.1:dns", wantErr: true},
+		{spec: "https:///dns-query", wantErr: true},
+		{spec: "udp://192.0.2.1/path", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseServer(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tt.want.Spec = tt.spec
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			if address := got.Address(); address != tt.wantAddress {
+				t.Errorf("got address %q, want %q", address, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestServerID(t *testing.T) {
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{spec: "192.0.2.1", want: "192.0.2.1"},
+		{spec: "192.0.2.1#lab", want: "192.0.2.1#lab"},
+		{spec: "udp://192.0.2.1#a", want: "udp://192.0.2.1#a"},
+		{spec: "tls://192.0.2.1#b", want: "tls://192.0.2.1#b"},
+		{spec: "tcp://[2001:db8::1]:5353", want: "tcp://[2001:db8::1]:5353"},
+		{spec: "quic://192.0.2.1", want: "quic://192.0.2.1"},
+		{spec: "https://dns.example/dns-query#doh", want: "https://dns.example/dns-query#doh"},
+	}
+
+	seen := make(map[string]string)
+	for _, tt := range tests {
+		server, err := ParseServer(tt.spec)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", tt.spec, err)
+		}
+
+		id := server.ID()
+		if id != tt.want {
+			t.Errorf("%q: got ID %q, want %q", tt.spec, id, tt.want)
+		}
+
+		if other, ok := seen[id]; ok {
+			t.Errorf("%q: ID %q is the same as for %q", tt.spec, id, other)
+		}
+		seen[id] = tt.spec
+	}
+}
@@ -9,7 +9,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
+	"time"
+
+	"github.com/atc0005/dnsc/internal/dqrs"
 
 	"github.com/apex/log"
 )
@@ -23,15 +27,25 @@ func (c Config) Validate() error {
 	// }
 	// log.Debugf("c.configFile validates: %#v", c.configFile)
 
-	if c.Servers() == nil || len(c.Servers()) == 0 {
+	if c.serverEntries() == nil || len(c.serverEntries()) == 0 {
 		return fmt.Errorf("one or more DNS servers not provided")
 	}
+	for _, entry := range c.serverEntries() {
+		if _, err := ParseServer(entry); err != nil {
+			return err
+		}
+	}
 	log.Debugf("c.Servers() validates: (%d entries) %#v", len(c.Servers()), c.Servers())
 
-	if c.Query() == "" {
+	if len(c.QueryRequests()) == 0 {
 		return fmt.Errorf("query not provided")
 	}
-	log.Debugf("c.Query() validates: %#v", c.Query())
+	for _, query := range c.Queries() {
+		if strings.TrimSpace(query) == "" {
+			return fmt.Errorf("empty query provided")
+		}
+	}
+	log.Debugf("c.Queries() validates: (%d entries) %#v", len(c.Queries()), c.Queries())
 
 	// We'll go ahead and provide a default
 	//
@@ -42,30 +56,30 @@ func (c Config) Validate() error {
 	// if not nil, assume that we're dealing with one or more requested record
 	// types
 	for _, queryType := range c.QueryTypes() {
-		switch strings.ToUpper(queryType) {
-		case RequestTypeA:
-		case RequestTypeAAAA:
-		case RequestTypeCNAME:
-		case RequestTypeNS:
-		case RequestTypeMX:
-		case RequestTypePTR:
-		case RequestTypeSRV:
-		default:
-			return fmt.Errorf(
-				"invalid option %q provided for request type",
-				queryType,
-			)
+		if err := validateQueryType(queryType); err != nil {
+			return err
 		}
 	}
 	log.Debugf("c.QueryTypes() validates: %#v", c.QueryTypes())
 
+	// Record types specified for individual queries loaded from a query file
+	// are subject to the same restrictions.
+	for _, request := range c.queryFileRequests {
+		for _, queryType := range request.Types {
+			if err := validateQueryType(queryType); err != nil {
+				return fmt.Errorf("invalid query file entry for %q: %w", request.Name, err)
+			}
+		}
+	}
+	log.Debugf("c.QueryFile() validates: (%d entries) %#v", len(c.queryFileRequests), c.QueryFile())
+
 	switch {
 	case len(c.SrvProtocols()) > 0:
 
 		// Perfectly acceptable to not specify a SRV protocol, but if specified,
 		// limit provided keywords to a valid list.
 		for _, queryType := range c.QueryTypes() {
-			if strings.ToUpper(queryType) == RequestTypeSRV {
+			if strings.ToUpper(queryType) == dqrs.RequestTypeSRV {
 				for _, srvProtocol := range c.SrvProtocols() {
 					_, err := SrvProtocolTmplLookup(srvProtocol)
 					if err != nil {
@@ -83,7 +97,7 @@ func (c Config) Validate() error {
 		// logic checks.
 		var srvTypeSpecified bool
 		for _, queryType := range c.QueryTypes() {
-			if strings.ToUpper(queryType) == RequestTypeSRV {
+			if strings.ToUpper(queryType) == dqrs.RequestTypeSRV {
 				srvTypeSpecified = true
 				for _, srvProtocol := range c.SrvProtocols() {
 					_, err := SrvProtocolTmplLookup(srvProtocol)
@@ -132,14 +146,193 @@ func (c Config) Validate() error {
 	switch c.ResultsOutput() {
 	case ResultsOutputSingleLine:
 	case ResultsOutputMultiLine:
+	case ResultsOutputJSON:
+	case ResultsOutputCSV:
+	case ResultsOutputTSV:
+	case ResultsOutputMarkdown:
+	case ResultsOutputHTML:
 	default:
 		return fmt.Errorf("invalid option %q provided for results summary output",
 			c.ResultsOutput())
 	}
 	log.Debugf("c.ResultsOutput() validates: %#v", c.ResultsOutput())
 
+	switch c.Transport() {
+	case TransportUDP:
+	case TransportTCP:
+	case TransportAuto:
+	case TransportTLS:
+	default:
+		return fmt.Errorf("invalid option %q provided for transport",
+			c.Transport())
+	}
+	log.Debugf("c.Transport() validates: %#v", c.Transport())
+
+	for _, entry := range c.TLSServerNames() {
+		server, name, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(server) == "" || strings.TrimSpace(name) == "" {
+			return fmt.Errorf(
+				"invalid option %q provided for TLS server name; expected SERVER=NAME",
+				entry,
+			)
+		}
+	}
+	log.Debugf("c.TLSServerNames() validates: %#v", c.TLSServerNames())
+
+	if c.BootstrapServer() != "" {
+		bootstrapServer, err := ParseServer(c.BootstrapServer())
+		switch {
+		case err != nil:
+			return fmt.Errorf("invalid bootstrap DNS server provided: %w", err)
+		case net.ParseIP(bootstrapServer.Host) == nil:
+			return fmt.Errorf(
+				"invalid bootstrap DNS server %q provided; an IP Address is required",
+				c.BootstrapServer(),
+			)
+		case bootstrapServer.Transport != "" || bootstrapServer.Label != "":
+			return fmt.Errorf(
+				"invalid bootstrap DNS server %q provided; only an IP Address and optional port are supported",
+				c.BootstrapServer(),
+			)
+		}
+	}
+	log.Debugf("c.BootstrapServer() validates: %#v", c.BootstrapServer())
+
+	switch c.DoHMethod() {
+	case DoHMethodGet:
+	case DoHMethodPost:
+	default:
+		return fmt.Errorf("invalid option %q provided for DoH method",
+			c.DoHMethod())
+	}
+	log.Debugf("c.DoHMethod() validates: %#v", c.DoHMethod())
+
+	if c.TLSCAFile() != "" && !PathExists(c.TLSCAFile()) {
+		return fmt.Errorf("specified CA bundle %q not found", c.TLSCAFile())
+	}
+	log.Debugf("c.TLSCAFile() validates: %#v", c.TLSCAFile())
+
+	if c.MaxConcurrency() < 1 {
+		return fmt.Errorf(
+			"invalid value %d provided for maximum concurrency; value must be 1 or greater",
+			c.MaxConcurrency(),
+		)
+	}
+	log.Debugf("c.MaxConcurrency() validates: %d", c.MaxConcurrency())
+
+	if c.MaxPerServer() < 1 {
+		return fmt.Errorf(
+			"invalid value %d provided for maximum per-server concurrency; value must be 1 or greater",
+			c.MaxPerServer(),
+		)
+	}
+	log.Debugf("c.MaxPerServer() validates: %d", c.MaxPerServer())
+
+	if c.RateLimit() < 0 {
+		return fmt.Errorf(
+			"invalid value %v provided for rate limit; value must be 0 or greater",
+			c.RateLimit(),
+		)
+	}
+	log.Debugf("c.RateLimit() validates: %v", c.RateLimit())
+
+	for _, entry := range c.ServerRateLimits() {
+		if _, err := parseServerRateLimit(entry); err != nil {
+			return err
+		}
+	}
+	log.Debugf("c.ServerRateLimits() validates: %#v", c.ServerRateLimits())
+
+	if c.Retries() < 0 || c.Retries() > maxRetries {
+		return fmt.Errorf(
+			"invalid value %d provided for retries; value must be between 0 and %d",
+			c.Retries(),
+			maxRetries,
+		)
+	}
+	log.Debugf("c.Retries() validates: %d", c.Retries())
+
+	if c.RetryBackoff() < 0 {
+		return fmt.Errorf(
+			"invalid value %v provided for retry backoff; value must be 0 or greater",
+			c.RetryBackoff(),
+		)
+	}
+	log.Debugf("c.RetryBackoff() validates: %v", c.RetryBackoff())
+
+	if c.RetryJitter() < 0 {
+		return fmt.Errorf(
+			"invalid value %v provided for retry jitter; value must be 0 or greater",
+			c.RetryJitter(),
+		)
+	}
+	log.Debugf("c.RetryJitter() validates: %v", c.RetryJitter())
+
+	if c.OutputTemplate() != "" {
+		if _, err := dqrs.NewOutputTemplate(c.OutputTemplate()); err != nil {
+			return fmt.Errorf("invalid output template provided: %w", err)
+		}
+	}
+	log.Debugf("c.OutputTemplate() validates: %q", c.OutputTemplate())
+
+	if c.PollInterval() < time.Second {
+		return fmt.Errorf(
+			"invalid value %v provided for poll interval; value must be 1 second or greater",
+			c.PollInterval(),
+		)
+	}
+	log.Debugf("c.PollInterval() validates: %v", c.PollInterval())
+
+	if c.WaitDeadline() < time.Second {
+		return fmt.Errorf(
+			"invalid value %v provided for wait deadline; value must be 1 second or greater",
+			c.WaitDeadline(),
+		)
+	}
+	log.Debugf("c.WaitDeadline() validates: %v", c.WaitDeadline())
+
+	if c.Watch() < 0 {
+		return fmt.Errorf(
+			"invalid value %v provided for watch interval; value must be 0 or greater",
+			c.Watch(),
+		)
+	}
+	if c.Watch() > 0 && c.WaitForConsistency() {
+		return fmt.Errorf("watch mode cannot be used when waiting for consistency")
+	}
+	log.Debugf("c.Watch() validates: %v", c.Watch())
+
+	if _, err := dqrs.NewExpectations(c.Expect()); err != nil {
+		return fmt.Errorf("invalid expected value provided: %w", err)
+	}
+	log.Debugf("c.Expect() validates: %v", c.Expect())
+
 	// Optimist
 	log.Debug("All validation checks pass")
 	return nil
 
 }
+
+// validateQueryType verifies that the given query type is a known Resource
+// Record type (or uses the generic TYPE### notation) which may be requested
+// using a standard query.
+func validateQueryType(queryType string) error {
+
+	rrType, err := dqrs.RRStringToType(queryType)
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option %q provided for request type: %w",
+			queryType,
+			err,
+		)
+	}
+
+	if !dqrs.QueryableType(rrType) {
+		return fmt.Errorf(
+			"invalid option %q provided for request type: type not supported for queries",
+			queryType,
+		)
+	}
+
+	return nil
+}
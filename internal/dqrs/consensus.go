@@ -0,0 +1,245 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// divergenceMarker flags DNS servers which provided an answer which differs
+// from the answer provided by the majority of DNS servers.
+const divergenceMarker = "!"
+
+// answerKey identifies the set of query responses which are expected to
+// return the same answer: those for the same query and requested record
+// type.
+type answerKey struct {
+	query  string
+	rrType uint16
+}
+
+// key returns the answerKey for the query response.
+func (dqr DNSQueryResponse) key() answerKey {
+	return answerKey{
+		query:  strings.ToLower(dqr.Query),
+		rrType: dqr.RequestedRecordType,
+	}
+}
+
+// answerSet returns a normalized representation of the answer provided by
+// the DNS server which may be compared against the answers provided by other
+// DNS servers for the same query. Records from the answer section are
+// compared without regard to order, case or TTL. If no records were
+// returned, the negative outcome (e.g., NXDOMAIN) is used instead.
+func (dqr DNSQueryResponse) answerSet() string {
+
+	if !dqr.Responded {
+		return "no response"
+	}
+
+	records := dqr.SectionRecords(SectionAnswer)
+	if len(records) == 0 {
+		if dqr.Rcode == dns.RcodeSuccess {
+			return "NODATA"
+		}
+		return dqr.RcodeString()
+	}
+
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Type+" "+strings.ToLower(record.Value))
+	}
+	sort.Strings(values)
+
+	return strings.Join(values, "\n")
+}
+
+// answered indicates whether the DNS server provided an answer which may be
+// compared against the answers provided by other DNS servers. Queries which
+// failed (e.g., timed out) or which the DNS server could not answer (e.g.,
+// SERVFAIL, REFUSED) did not provide an answer; a missing name (NXDOMAIN) or
+// record type (NODATA) is an answer.
+func (dqr DNSQueryResponse) answered() bool {
+	return dqr.Responded &&
+		(dqr.QueryError == nil || errors.Is(dqr.QueryError, ErrNoRecordsFound))
+}
+
+// answerGroup is the collection of answer sets provided by DNS servers for
+// the same query and requested record type.
+type answerGroup struct {
+
+	// counts is the number of DNS servers which provided each answer set.
+	counts map[string]int
+
+	// total is the number of DNS servers which provided an answer.
+	total int
+
+	// majority is the answer set provided by more than half of the DNS
+	// servers which provided an answer. This is empty if there is no such
+	// answer set.
+	majority string
+
+	// hasMajority indicates whether a single answer set was provided by
+	// more than half of the DNS servers which provided an answer.
+	hasMajority bool
+}
+
+// Consensus is the result of grouping DNS servers by the answer provided for
+// each query and requested record type in order to identify DNS servers
+// which disagree with the majority (e.g., servers still returning a previous
+// IP Address after a change).
+type Consensus struct {
+	groups map[answerKey]*answerGroup
+}
+
+// ConsensusResult is the outcome of comparing the answer provided by a DNS
+// server against the answers provided by all DNS servers for the same query
+// and requested record type.
+type ConsensusResult struct {
+
+	// Answered indicates whether the DNS server provided an answer. DNS
+	// servers which did not (e.g., the query timed out) are not compared
+	// against the majority answer.
+	Answered bool
+
+	// HasMajority indicates whether a single answer was provided by more
+	// than half of the DNS servers which provided an answer. If not, no DNS
+	// server is considered an outlier.
+	HasMajority bool
+
+	// Outlier indicates whether the DNS server provided an answer which
+	// differs from the majority answer.
+	Outlier bool
+
+	// MajorityServers is the number of DNS servers which provided the
+	// majority answer.
+	MajorityServers int
+
+	// TotalServers is the number of DNS servers which provided an answer for
+	// the same query and requested record type.
+	TotalServers int
+}
+
+// Consensus groups DNS servers by the normalized answer provided for each
+// query and requested record type and identifies the majority answer for
+// each. Query responses which did not provide an answer are not counted.
+func (dqrs DNSQueryResponses) Consensus() Consensus {
+
+	groups := make(map[answerKey]*answerGroup)
+	for _, item := range dqrs {
+		key := item.key()
+		group, ok := groups[key]
+		if !ok {
+			group = &answerGroup{counts: make(map[string]int)}
+			groups[key] = group
+		}
+
+		if !item.answered() {
+			continue
+		}
+
+		group.counts[item.answerSet()]++
+		group.total++
+	}
+
+	for _, group := range groups {
+		for answerSet, count := range group.counts {
+			if count*2 > group.total {
+				group.majority, group.hasMajority = answerSet, true
+				break
+			}
+		}
+	}
+
+	return Consensus{groups: groups}
+}
+
+// Result returns the outcome of comparing the answer provided by the DNS
+// server for the given query response against the majority answer.
+func (c Consensus) Result(dqr DNSQueryResponse) ConsensusResult {
+
+	group, ok := c.groups[dqr.key()]
+	if !ok {
+		return ConsensusResult{}
+	}
+
+	result := ConsensusResult{
+		Answered:     dqr.answered(),
+		HasMajority:  group.hasMajority,
+		TotalServers: group.total,
+	}
+
+	if group.hasMajority {
+		result.MajorityServers = group.counts[group.majority]
+		result.Outlier = result.Answered && dqr.answerSet() != group.majority
+	}
+
+	return result
+}
+
+// Note returns a short note describing how the answer differs from the
+// majority answer (e.g., "diverges from 3/4 servers") or an empty string if
+// the DNS server is not an outlier.
+func (cr ConsensusResult) Note() string {
+
+	if !cr.Outlier {
+		return ""
+	}
+
+	return fmt.Sprintf("diverges from %d/%d servers", cr.MajorityServers, cr.TotalServers)
+}
+
+// String returns a short description of the outcome, such as "agrees with
+// 3/4 servers" or "diverges from 3/4 servers".
+func (cr ConsensusResult) String() string {
+
+	switch {
+	case !cr.Answered:
+		return "no answer"
+	case !cr.HasMajority:
+		return "no majority"
+	case cr.Outlier:
+		return cr.Note()
+	default:
+		return fmt.Sprintf("agrees with %d/%d servers", cr.MajorityServers, cr.TotalServers)
+	}
+}
+
+// divergenceNotes returns a note for each DNS server which provided an answer
+// which differs from the majority answer, such as "lab: www.example.com (A)
+// diverges from 3/4 servers".
+func (dqrs DNSQueryResponses) divergenceNotes(consensus Consensus) []string {
+
+	var notes []string
+	for _, item := range dqrs {
+		result := consensus.Result(item)
+		if !result.Outlier {
+			continue
+		}
+
+		requestType, err := RRTypeToString(item.RequestedRecordType)
+		if err != nil {
+			requestType = "rrString LookupError"
+		}
+
+		notes = append(notes, fmt.Sprintf(
+			"%s: %s (%s) %s",
+			item.ServerName(),
+			item.Query,
+			requestType,
+			result.Note(),
+		))
+	}
+
+	return notes
+}
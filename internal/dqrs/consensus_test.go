@@ -0,0 +1,158 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// testResponse builds a query response for an A record query submitted to
+// the given server which returned the given IP Addresses.
+func testResponse(server string, addresses ...string) DNSQueryResponse {
+
+	dqr := DNSQueryResponse{
+		Server:              server,
+		Query:               "www.example.com",
+		RequestedRecordType: dns.TypeA,
+		Attempts:            1,
+		Responded:           true,
+	}
+
+	for _, address := range addresses {
+		dqr.Answer = append(dqr.Answer, &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   "www.example.com.",
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    300,
+			},
+			A: net.ParseIP(address),
+		})
+	}
+
+	return dqr
+}
+
+// testFailedResponse builds a query response for an A record query submitted
+// to the given server which failed with the given error.
+func testFailedResponse(server string, responded bool, rcode int, err error) DNSQueryResponse {
+
+	dqr := testResponse(server)
+	dqr.Responded = responded
+	dqr.Rcode = rcode
+	dqr.QueryError = err
+
+	return dqr
+}
+
+func TestConsensus(t *testing.T) {
+
+	timeout := errors.New("i/o timeout")
+
+	tests := []struct {
+		name         string
+		responses    DNSQueryResponses
+		wantOutliers []bool
+		wantMajority bool
+		wantTotal    int
+	}{
+		{
+			name: "outlier",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+				testResponse("s3", "192.0.2.2"),
+			},
+			wantOutliers: []bool{false, false, true},
+			wantMajority: true,
+			wantTotal:    3,
+		},
+		{
+			name: "failed queries excluded",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testFailedResponse("s2", false, 0, timeout),
+				testFailedResponse("s3", false, 0, timeout),
+			},
+			wantOutliers: []bool{false, false, false},
+			wantMajority: true,
+			wantTotal:    1,
+		},
+		{
+			name: "error responses excluded",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testFailedResponse("s2", true, dns.RcodeServerFailure, errors.New("SERVFAIL")),
+				testFailedResponse("s3", true, dns.RcodeRefused, errors.New("REFUSED")),
+			},
+			wantOutliers: []bool{false, false, false},
+			wantMajority: true,
+			wantTotal:    1,
+		},
+		{
+			name: "negative answers included",
+			responses: DNSQueryResponses{
+				testFailedResponse("s1", true, dns.RcodeNameError, ErrNoRecordsFound),
+				testFailedResponse("s2", true, dns.RcodeNameError, ErrNoRecordsFound),
+				testResponse("s3", "192.0.2.1"),
+			},
+			wantOutliers: []bool{false, false, true},
+			wantMajority: true,
+			wantTotal:    3,
+		},
+		{
+			name: "plurality is not a majority",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+				testResponse("s3", "192.0.2.2"),
+				testResponse("s4", "192.0.2.3"),
+				testResponse("s5", "192.0.2.4"),
+			},
+			wantOutliers: []bool{false, false, false, false, false},
+			wantMajority: false,
+			wantTotal:    5,
+		},
+		{
+			name: "tie",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.2"),
+			},
+			wantOutliers: []bool{false, false},
+			wantMajority: false,
+			wantTotal:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consensus := tt.responses.Consensus()
+
+			for i, item := range tt.responses {
+				result := consensus.Result(item)
+
+				if result.Outlier != tt.wantOutliers[i] {
+					t.Errorf("%s: got outlier %t, want %t", item.Server, result.Outlier, tt.wantOutliers[i])
+				}
+
+				if result.HasMajority != tt.wantMajority {
+					t.Errorf("%s: got majority %t, want %t", item.Server, result.HasMajority, tt.wantMajority)
+				}
+
+				if result.TotalServers != tt.wantTotal {
+					t.Errorf("%s: got %d total servers, want %d", item.Server, result.TotalServers, tt.wantTotal)
+				}
+			}
+		})
+	}
+}
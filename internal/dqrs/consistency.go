@@ -0,0 +1,201 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// ResponseKey identifies the response to a query submitted to a specific DNS
+// server entry.
+type ResponseKey struct {
+
+	// Server is the unique identifier for the DNS server entry (see
+	// DNSQueryResponse.ServerID).
+	Server     string
+	Query      string
+	RecordType uint16
+}
+
+// ResponseKey returns the ResponseKey for the query response.
+func (dqr DNSQueryResponse) ResponseKey() ResponseKey {
+
+	server := dqr.ServerID
+	if server == "" {
+		server = dqr.Server
+	}
+
+	return ResponseKey{
+		Server:     server,
+		Query:      dqr.Query,
+		RecordType: dqr.RequestedRecordType,
+	}
+}
+
+// Converged indicates for each query response whether the DNS server has
+// converged on the final answer. If expected values were provided for the
+// requested record type, a DNS server has converged once its answer matches
+// the expected values. Otherwise, as there is no way to tell which answer is
+// current (early in a change the majority of DNS servers may still provide
+// the previous answer), DNS servers have only converged once all DNS servers
+// provide the same answer for the same query. Queries which did not provide
+// an answer (e.g., timed out) have not converged and prevent all other DNS
+// servers for the same query from converging.
+func (dqrs DNSQueryResponses) Converged(e Expectations) map[ResponseKey]bool {
+
+	answerSets := make(map[answerKey]string)
+	unanimous := make(map[answerKey]bool)
+	for _, item := range dqrs {
+		key := item.key()
+		answerSet, seen := answerSets[key]
+		switch {
+		case !seen:
+			answerSets[key] = item.answerSet()
+			unanimous[key] = item.answered()
+		case !item.answered() || item.answerSet() != answerSet:
+			unanimous[key] = false
+		}
+	}
+
+	converged := make(map[ResponseKey]bool, len(dqrs))
+	for _, item := range dqrs {
+		if result := e.Check(item); result.Status != "" {
+			converged[item.ResponseKey()] = result.Status == ExpectationPass
+			continue
+		}
+
+		converged[item.ResponseKey()] = unanimous[item.key()]
+	}
+
+	return converged
+}
+
+// timelineEntry is the answer provided by a DNS server for a single poll.
+type timelineEntry struct {
+	at     time.Time
+	answer string
+}
+
+// ConsistencyTimeline records the answers provided by each DNS server over
+// multiple polls in order to determine when each DNS server converged on the
+// final answer.
+type ConsistencyTimeline struct {
+	start     time.Time
+	order     []ResponseKey
+	responses map[ResponseKey]DNSQueryResponse
+	entries   map[ResponseKey][]timelineEntry
+}
+
+// NewConsistencyTimeline creates an empty timeline which starts at the given
+// time.
+func NewConsistencyTimeline(start time.Time) *ConsistencyTimeline {
+	return &ConsistencyTimeline{
+		start:     start,
+		responses: make(map[ResponseKey]DNSQueryResponse),
+		entries:   make(map[ResponseKey][]timelineEntry),
+	}
+}
+
+// Record adds the given query responses from a poll performed at the given
+// time to the timeline.
+func (t *ConsistencyTimeline) Record(at time.Time, responses DNSQueryResponses) {
+
+	for _, item := range responses {
+		key := item.ResponseKey()
+		if _, ok := t.responses[key]; !ok {
+			t.order = append(t.order, key)
+		}
+
+		t.responses[key] = item
+		t.entries[key] = append(t.entries[key], timelineEntry{at: at, answer: item.answerSet()})
+	}
+}
+
+// Latest returns the most recent response for each query submitted to each
+// DNS server.
+func (t *ConsistencyTimeline) Latest() DNSQueryResponses {
+
+	latest := make(DNSQueryResponses, 0, len(t.order))
+	for _, key := range t.order {
+		latest = append(latest, t.responses[key])
+	}
+
+	return latest
+}
+
+// convergedAt returns the time of the first poll from which the DNS server
+// consistently provided its most recent answer along with the number of
+// polls performed.
+func (t *ConsistencyTimeline) convergedAt(key ResponseKey) (time.Time, int) {
+
+	entries := t.entries[key]
+	if len(entries) == 0 {
+		return time.Time{}, 0
+	}
+
+	first := len(entries) - 1
+	for first > 0 && entries[first-1].answer == entries[len(entries)-1].answer {
+		first--
+	}
+
+	return entries[first].at, len(entries)
+}
+
+// PrintTimeline lists when each DNS server converged on the final answer.
+// This is written to the supplementary output (see SummaryOptions.auxOutput).
+func (t *ConsistencyTimeline) PrintTimeline(converged map[ResponseKey]bool, opts SummaryOptions) {
+
+	if err := t.writeTimeline(opts.auxOutput(), converged); err != nil {
+		log.Errorf("Error generating consistency timeline: %v", err)
+	}
+}
+
+// writeTimeline writes when each DNS server converged on the final answer to
+// the given writer as a table with aligned columns.
+func (t *ConsistencyTimeline) writeTimeline(output io.Writer, converged map[ResponseKey]bool) error {
+
+	w := newTableWriter(output)
+
+	_, _ = fmt.Fprintf(w, "\n\nConsistency timeline:\n\n")
+
+	columns := []string{"Server", "Query", "Type", "Converged", "Elapsed", "Polls", "Answer"}
+	writeTableHeader(w, columns)
+
+	for _, key := range t.order {
+		item := t.responses[key]
+		at, polls := t.convergedAt(key)
+
+		convergedTime := "not converged"
+		elapsed := ""
+		if converged[key] {
+			convergedTime = at.Format(time.TimeOnly)
+			elapsed = at.Sub(t.start).Round(time.Second).String()
+		}
+
+		writeSummaryRow(w, columns, []string{
+			item.ServerName(),
+			item.Query,
+			RecordType(item.RequestedRecordType),
+			convergedTime,
+			elapsed,
+			fmt.Sprint(polls),
+			strings.ReplaceAll(item.answerSet(), "\n", ", "),
+		})
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error flushing tabwriter: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConverged(t *testing.T) {
+
+	expectations, err := NewExpectations(map[string][]string{"A": {"192.0.2.2"}})
+	if err != nil {
+		t.Fatalf("failed to create expectations: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		responses    DNSQueryResponses
+		expectations Expectations
+		want         []bool
+	}{
+		{
+			name: "unanimous",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+			},
+			want: []bool{true, true},
+		},
+		{
+			// The majority may still provide the previous answer, so DNS
+			// servers agreeing with the majority have not converged.
+			name: "stale majority",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+				testResponse("s3", "192.0.2.2"),
+			},
+			want: []bool{false, false, false},
+		},
+		{
+			name: "failed query",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testFailedResponse("s2", false, 0, errors.New("i/o timeout")),
+			},
+			want: []bool{false, false},
+		},
+		{
+			name: "expected values",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+				testResponse("s3", "192.0.2.2"),
+			},
+			expectations: expectations,
+			want:         []bool{false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converged := tt.responses.Converged(tt.expectations)
+
+			for i, item := range tt.responses {
+				if got := converged[item.ResponseKey()]; got != tt.want[i] {
+					t.Errorf("%s: got converged %t, want %t", item.Server, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -7,12 +7,63 @@
 
 package dqrs
 
+import "github.com/miekg/dns"
+
 // defaultDNSPort is the default UDP and TCP port used for incoming DNS
 // requests
 const defaultDNSPort = "53"
 
-// Supported Request types
+// defaultDoTPort is the default TCP port used for incoming DNS-over-TLS (DoT)
+// requests
+const defaultDoTPort = "853"
+
+// defaultDoQPort is the default UDP port used for incoming DNS-over-QUIC
+// (DoQ) requests
+const defaultDoQPort = "853"
+
+// Supported transports used to submit DNS queries.
+// TODO: Duplicated in config package
+const (
+	// TransportUDP submits queries using UDP only. Truncated responses are
+	// returned as-is.
+	TransportUDP string = "udp"
+
+	// TransportTCP submits queries using TCP only.
+	TransportTCP string = "tcp"
+
+	// TransportAuto submits queries using UDP and retries using TCP if the
+	// server indicates that the response was truncated (TC bit set).
+	TransportAuto string = "auto"
+
+	// TransportTLS submits queries using DNS-over-TLS (DoT).
+	TransportTLS string = "tls"
+
+	// TransportHTTPS submits queries using DNS-over-HTTPS (DoH). This
+	// transport is used for servers specified as https:// URLs.
+	TransportHTTPS string = "https"
+
+	// TransportQUIC submits queries using DNS-over-QUIC (DoQ). This
+	// transport is used for servers specified using the quic:// scheme.
+	TransportQUIC string = "quic"
+)
+
+// Supported HTTP methods used to submit DNS-over-HTTPS (DoH) queries.
 // TODO: Duplicated in config package
+const (
+	DoHMethodGet  string = "get"
+	DoHMethodPost string = "post"
+)
+
+// dohMediaType is the media type used for DNS-over-HTTPS (DoH) requests and
+// responses per RFC 8484.
+const dohMediaType string = "application/dns-message"
+
+// dohMaxResponseSize is the maximum size in bytes of a DNS-over-HTTPS (DoH)
+// response body that we are willing to read. This matches the maximum size
+// of a DNS message.
+const dohMaxResponseSize int64 = dns.MaxMsgSize
+
+// Commonly requested Resource Record types
 const (
 	RequestTypeA       string = "A"
 	RequestTypeAAAA    string = "AAAA"
@@ -24,11 +75,21 @@ const (
 	RequestTypeUnknown string = "UNKNOWN"
 )
 
-const recordValueUnknown string = "type unknown"
-
 // Results summary output display options
 // TODO: Duplicated in config package
 const (
 	ResultsOutputSingleLine string = "single-line"
 	ResultsOutputMultiLine  string = "multi-line"
+	ResultsOutputJSON       string = "json"
+	ResultsOutputCSV        string = "csv"
+	ResultsOutputTSV        string = "tsv"
+	ResultsOutputMarkdown   string = "markdown"
+	ResultsOutputHTML       string = "html"
+)
+
+// Sections of a query response that records may be returned in
+const (
+	SectionAnswer     string = "answer"
+	SectionAuthority  string = "authority"
+	SectionAdditional string = "additional"
 )
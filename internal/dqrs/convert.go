@@ -2,41 +2,73 @@ package dqrs
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/miekg/dns"
 )
 
+// rfc3597TypePrefix is the prefix used by the generic TYPE### notation for
+// Resource Record types described in RFC 3597.
+const rfc3597TypePrefix string = "TYPE"
+
 // RRTypeToString converts a known Resource Record type to the appropriate
-// internal Resource Record string value. An error is returned if the Resource
-// Record type is not a valid key in the dns.TypeToString map.
+// internal Resource Record string value. Types which are not a valid key in
+// the dns.TypeToString map are converted using the generic TYPE### notation
+// from RFC 3597. An error is returned if the Resource Record type is zero.
 func RRTypeToString(rrType uint16) (string, error) {
 
-	// what checks can we run against rrType aside from validating whether it
-	// exists as a key in the map?
-
-	rrString, ok := dns.TypeToString[rrType]
-	if !ok {
-		return "", fmt.Errorf("provided key %v not in dns.TypeToString map", rrType)
+	if rrType == dns.TypeNone {
+		return "", fmt.Errorf("provided key %v is not a valid Resource Record type", rrType)
 	}
 
-	return rrString, nil
+	return RecordType(rrType), nil
 
 }
 
 // RRStringToType converts a known Resource Record string value to the
-// appropriate internal Resource Record type. An error is returned if the
-// string value is not a valid key in the dns.StringToType map.
+// appropriate internal Resource Record type. In addition to the keys in the
+// dns.StringToType map, the generic TYPE### notation from RFC 3597 is
+// accepted (e.g., TYPE65 for HTTPS records). An error is returned if the
+// string value is not a valid key in the dns.StringToType map or a valid
+// numeric type.
 func RRStringToType(rrString string) (uint16, error) {
 
 	if rrString == "" {
 		return 0, fmt.Errorf("empty rrString argument given")
 	}
 
-	rrType, ok := dns.StringToType[strings.ToUpper(rrString)]
-	if !ok {
-		return 0, fmt.Errorf("provided key %v not in dns.StringToType map", rrString)
+	rrString = strings.ToUpper(rrString)
+
+	if rrType, ok := dns.StringToType[rrString]; ok {
+		return rrType, nil
+	}
+
+	if numericType, found := strings.CutPrefix(rrString, rfc3597TypePrefix); found {
+		rrType, err := strconv.ParseUint(numericType, 10, 16)
+		if err == nil && rrType != uint64(dns.TypeNone) {
+			return uint16(rrType), nil
+		}
 	}
 
-	return rrType, nil
+	return 0, fmt.Errorf("provided key %v not in dns.StringToType map", rrString)
+}
+
+// QueryableType indicates whether the given Resource Record type may be
+// requested using a standard query. Meta types used for zone transfers,
+// EDNS(0) and transaction signatures are excluded.
+func QueryableType(rrType uint16) bool {
+
+	switch rrType {
+	case dns.TypeNone,
+		dns.TypeOPT,
+		dns.TypeAXFR,
+		dns.TypeIXFR,
+		dns.TypeTSIG,
+		dns.TypeTKEY,
+		dns.TypeReserved:
+		return false
+	default:
+		return true
+	}
 }
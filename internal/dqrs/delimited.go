@@ -0,0 +1,119 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// delimitedColumns is the header row for delimited (CSV, TSV) results
+// output.
+var delimitedColumns = []string{
+	"server",
+	"server_label",
+	"server_hostname",
+	"query",
+	"type",
+	"transport",
+	"rtt_ms",
+	"attempts",
+	"rcode",
+	"flags",
+	"error",
+	"outlier",
+	"consensus",
+	"changed",
+	"section",
+	"record_type",
+	"ttl",
+	"value",
+}
+
+// writeDelimited writes all collected DNS query results to the given writer
+// using the specified field delimiter with a header row and one row for each
+// record. A single row without record details is written for queries which
+// did not return any records. Values containing the delimiter or quotes
+// (e.g., TXT records) are quoted as needed.
+func (dqrs DNSQueryResponses) writeDelimited(w io.Writer, delimiter rune, opts SummaryOptions) error {
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write(delimitedColumns); err != nil {
+		return fmt.Errorf("failed to write header row: %w", err)
+	}
+
+	consensus := dqrs.Consensus()
+	changed := dqrs.Changed(opts.Previous)
+
+	for _, item := range dqrs {
+
+		requestType, err := RRTypeToString(item.RequestedRecordType)
+		if err != nil {
+			requestType = ""
+		}
+
+		result := consensus.Result(item)
+
+		var queryError string
+		if item.QueryError != nil {
+			queryError = item.QueryError.Error()
+		}
+
+		leadingFields := []string{
+			item.Server,
+			item.ServerLabel,
+			item.ServerHostname,
+			item.Query,
+			requestType,
+			item.Transport,
+			strconv.FormatFloat(durationToMilliseconds(item.ResponseTime), 'f', 3, 64),
+			strconv.Itoa(item.Attempts),
+			item.RcodeString(),
+			item.flags(),
+			queryError,
+			strconv.FormatBool(result.Outlier),
+			result.Note(),
+			strconv.FormatBool(changed[item.ResponseKey()]),
+		}
+
+		// Sort records so that output is consistent between runs
+		item.SortRecordsAsc()
+
+		records := item.SectionRecords(opts.sections()...)
+		if len(records) == 0 {
+			if err := writer.Write(append(leadingFields, "", "", "", "")); err != nil {
+				return fmt.Errorf("failed to write row: %w", err)
+			}
+			continue
+		}
+
+		for _, record := range records {
+			row := append(
+				leadingFields[:len(leadingFields):len(leadingFields)],
+				record.Section,
+				record.Type,
+				strconv.FormatUint(uint64(record.TTL), 10),
+				record.Value,
+			)
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write row: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush output: %w", err)
+	}
+
+	return nil
+}
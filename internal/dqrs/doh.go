@@ -0,0 +1,119 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ErrUnexpectedHTTPStatus indicates that a DNS-over-HTTPS (DoH) server
+// returned a HTTP status code other than 200 OK.
+var ErrUnexpectedHTTPStatus = errors.New("unexpected HTTP status code")
+
+// isDoHServer indicates whether the given server is specified as a
+// DNS-over-HTTPS (DoH) URL.
+func isDoHServer(server string) bool {
+	return strings.HasPrefix(strings.ToLower(server), "https://")
+}
+
+// exchangeDoH submits the given message to the specified DNS-over-HTTPS (DoH)
+// server URL using RFC 8484 wire-format requests. The HTTP status code is
+// recorded along with the response and round-trip time.
+func exchangeDoH(msg *dns.Msg, serverURL string, opts QueryOptions) (exchangeResult, error) {
+
+	result := exchangeResult{transport: TransportHTTPS}
+
+	endpoint, err := url.Parse(serverURL)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse DoH server URL: %w", err)
+	}
+
+	// RFC 8484 recommends using a message ID of zero in order to improve
+	// cache friendliness of GET requests. We work from a copy to avoid
+	// modifying the caller's message.
+	dohMsg := msg.Copy()
+	dohMsg.Id = 0
+
+	packed, err := dohMsg.Pack()
+	if err != nil {
+		return result, fmt.Errorf("failed to pack DNS message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	var request *http.Request
+	switch strings.ToLower(opts.DoHMethod) {
+	case DoHMethodGet:
+		query := endpoint.Query()
+		query.Set("dns", base64.RawURLEncoding.EncodeToString(packed))
+		endpoint.RawQuery = query.Encode()
+
+		request, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+
+	default:
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(packed))
+		if err == nil {
+			request.Header.Set("Content-Type", dohMediaType)
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to prepare DoH request: %w", err)
+	}
+	request.Header.Set("Accept", dohMediaType)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = opts.tlsConfig(endpoint.Hostname())
+	client := http.Client{Transport: transport}
+	defer client.CloseIdleConnections()
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		result.rtt = time.Since(start)
+		return result, err
+	}
+
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, dohMaxResponseSize))
+	result.rtt = time.Since(start)
+	result.httpStatus = response.StatusCode
+	if err != nil {
+		return result, fmt.Errorf("failed to read DoH response: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("%w: %s", ErrUnexpectedHTTPStatus, response.Status)
+	}
+
+	in := new(dns.Msg)
+	if err := in.Unpack(body); err != nil {
+		return result, fmt.Errorf("failed to unpack DoH response: %w", err)
+	}
+
+	// Restore the original message ID so that the response matches the
+	// submitted query.
+	in.Id = msg.Id
+	result.response = in
+
+	return result, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testDoHRequest records the details of a request received by the local
+// test DNS-over-HTTPS (DoH) server.
+type testDoHRequest struct {
+	method      string
+	contentType string
+	id          uint16
+}
+
+// startTestDoHServer starts a local DNS-over-HTTPS (DoH) server presenting
+// the given certificate. Requests are answered with the given HTTP status
+// code. The URL of the DoH endpoint is returned along with a function which
+// returns the details of all requests received.
+func startTestDoHServer(t *testing.T, cert tls.Certificate, status int) (string, func() []testDoHRequest) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []testDoHRequest
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var packed []byte
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			packed, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+		default:
+			packed, err = io.ReadAll(r.Body)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := new(dns.Msg)
+		if err := query.Unpack(packed); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, testDoHRequest{
+			method:      r.Method,
+			contentType: r.Header.Get("Content-Type"),
+			id:          query.Id,
+		})
+		mu.Unlock()
+
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		response, err := testReply(query).Pack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", dohMediaType)
+		_, _ = w.Write(response)
+	}))
+
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.URL + "/dns-query", func() []testDoHRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]testDoHRequest(nil), requests...)
+	}
+}
+
+func TestExchangeDoH(t *testing.T) {
+
+	cert, caFile := newTestCertificate(t)
+
+	tlsConfig, err := NewTLSConfig(caFile, false)
+	if err != nil {
+		t.Fatalf("failed to create TLS config: %v", err)
+	}
+
+	tests := []struct {
+		method          string
+		wantMethod      string
+		wantContentType string
+	}{
+		{method: DoHMethodGet, wantMethod: http.MethodGet},
+		{method: DoHMethodPost, wantMethod: http.MethodPost, wantContentType: dohMediaType},
+		{method: "", wantMethod: http.MethodPost, wantContentType: dohMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantMethod+"/"+tt.method, func(t *testing.T) {
+			serverURL, received := startTestDoHServer(t, cert, http.StatusOK)
+
+			msg := testQuery()
+			result, err := exchange(msg, serverURL, QueryOptions{
+				Timeout:   5 * time.Second,
+				TLSConfig: tlsConfig,
+				DoHMethod: tt.method,
+			})
+			if err != nil {
+				t.Fatalf("DoH exchange failed: %v", err)
+			}
+
+			if result.transport != TransportHTTPS {
+				t.Errorf("got transport %q, want %q", result.transport, TransportHTTPS)
+			}
+
+			if result.httpStatus != http.StatusOK {
+				t.Errorf("got HTTP status %d, want %d", result.httpStatus, http.StatusOK)
+			}
+
+			requests := received()
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(requests))
+			}
+
+			if requests[0].method != tt.wantMethod {
+				t.Errorf("got HTTP method %q, want %q", requests[0].method, tt.wantMethod)
+			}
+
+			if requests[0].contentType != tt.wantContentType {
+				t.Errorf("got Content-Type %q, want %q", requests[0].contentType, tt.wantContentType)
+			}
+
+			// RFC 8484 recommends a message ID of zero on the wire. The
+			// original message ID is restored in the response.
+			if requests[0].id != 0 {
+				t.Errorf("got query message ID %#x, want 0x0", requests[0].id)
+			}
+
+			if result.response.Id != msg.Id {
+				t.Errorf("got response message ID %#x, want %#x", result.response.Id, msg.Id)
+			}
+
+			if len(result.response.Answer) != 1 {
+				t.Fatalf("got %d answer records, want 1", len(result.response.Answer))
+			}
+
+			a, ok := result.response.Answer[0].(*dns.A)
+			if !ok || a.A.String() != testAnswer {
+				t.Errorf("got answer %v, want A %s", result.response.Answer[0], testAnswer)
+			}
+		})
+	}
+}
+
+func TestPerformQueryDoHUnexpectedStatus(t *testing.T) {
+
+	cert, caFile := newTestCertificate(t)
+	serverURL, _ := startTestDoHServer(t, cert, http.StatusServiceUnavailable)
+
+	tlsConfig, err := NewTLSConfig(caFile, false)
+	if err != nil {
+		t.Fatalf("failed to create TLS config: %v", err)
+	}
+
+	dqr := PerformQuery("www.example.com", serverURL, dns.TypeA, QueryOptions{
+		Timeout:   5 * time.Second,
+		TLSConfig: tlsConfig,
+	})
+
+	if !errors.Is(dqr.QueryError, ErrUnexpectedHTTPStatus) {
+		t.Errorf("got error %v, want %v", dqr.QueryError, ErrUnexpectedHTTPStatus)
+	}
+
+	if dqr.HTTPStatus != http.StatusServiceUnavailable {
+		t.Errorf("got HTTP status %d, want %d", dqr.HTTPStatus, http.StatusServiceUnavailable)
+	}
+
+	if dqr.Transport != TransportHTTPS {
+		t.Errorf("got transport %q, want %q", dqr.Transport, TransportHTTPS)
+	}
+
+	if dqr.Responded {
+		t.Error("query response recorded as responded")
+	}
+}
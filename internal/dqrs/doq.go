@@ -0,0 +1,126 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+)
+
+// doqALPN is the Application-Layer Protocol Negotiation (ALPN) token used to
+// identify DNS-over-QUIC (DoQ) connections per RFC 9250.
+const doqALPN string = "doq"
+
+// doqNoError is the DOQ_NO_ERROR application error code used when closing a
+// DNS-over-QUIC (DoQ) connection once a query has completed.
+const doqNoError quic.ApplicationErrorCode = 0x0
+
+// doqScheme is the URI scheme prefix used to specify DNS-over-QUIC (DoQ)
+// servers.
+const doqScheme string = "quic://"
+
+// isDoQServer indicates whether the given server is specified using the
+// DNS-over-QUIC (DoQ) quic:// scheme.
+func isDoQServer(server string) bool {
+	return strings.HasPrefix(strings.ToLower(server), doqScheme)
+}
+
+// exchangeDoQ submits the given message to the specified DNS-over-QUIC (DoQ)
+// server per RFC 9250. A new connection is established for each query and
+// the query is sent on a dedicated bidirectional stream.
+func exchangeDoQ(msg *dns.Msg, server string, opts QueryOptions) (exchangeResult, error) {
+
+	result := exchangeResult{transport: TransportQUIC}
+
+	host, remoteAddress := splitServerAddress(server[len(doqScheme):], defaultDoQPort)
+
+	// RFC 9250 requires that the message ID be set to zero. We work from a
+	// copy to avoid modifying the caller's message.
+	doqMsg := msg.Copy()
+	doqMsg.Id = 0
+
+	packed, err := doqMsg.Pack()
+	if err != nil {
+		return result, fmt.Errorf("failed to pack DNS message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+
+	tlsConfig := opts.tlsConfig(host)
+	tlsConfig.NextProtos = []string{doqALPN}
+
+	start := time.Now()
+
+	conn, err := quic.DialAddr(ctx, remoteAddress, tlsConfig, &quic.Config{
+		HandshakeIdleTimeout: opts.Timeout,
+	})
+	if err != nil {
+		result.rtt = time.Since(start)
+		return result, fmt.Errorf("failed to establish DoQ connection: %w", err)
+	}
+
+	defer func() {
+		_ = conn.CloseWithError(doqNoError, "")
+	}()
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		result.rtt = time.Since(start)
+		return result, fmt.Errorf("failed to open DoQ stream: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	// Each message is prefixed with a two-octet length field. The client
+	// indicates that no further data will be sent by closing the sending
+	// direction of the stream.
+	request := make([]byte, 2+len(packed))
+	binary.BigEndian.PutUint16(request, uint16(len(packed))) // #nosec G115
+	copy(request[2:], packed)
+
+	if _, err := stream.Write(request); err != nil {
+		result.rtt = time.Since(start)
+		return result, fmt.Errorf("failed to send DoQ query: %w", err)
+	}
+	_ = stream.Close()
+
+	var length uint16
+	if err := binary.Read(stream, binary.BigEndian, &length); err != nil {
+		result.rtt = time.Since(start)
+		return result, fmt.Errorf("failed to read DoQ response length: %w", err)
+	}
+
+	response := make([]byte, length)
+	_, err = io.ReadFull(stream, response)
+	result.rtt = time.Since(start)
+	if err != nil {
+		return result, fmt.Errorf("failed to read DoQ response: %w", err)
+	}
+
+	in := new(dns.Msg)
+	if err := in.Unpack(response); err != nil {
+		return result, fmt.Errorf("failed to unpack DoQ response: %w", err)
+	}
+
+	// Restore the original message ID so that the response matches the
+	// submitted query.
+	in.Id = msg.Id
+	result.response = in
+
+	return result, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+)
+
+// startTestDoQServer starts a local DNS-over-QUIC (DoQ) server presenting
+// the given certificate. The address of the server is returned along with a
+// function which returns the message IDs of all queries received.
+func startTestDoQServer(t *testing.T, cert tls.Certificate) (string, func() []uint16) {
+	t.Helper()
+
+	listener, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{doqALPN},
+		MinVersion:   tls.VersionTLS13,
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to start DoQ listener: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	var mu sync.Mutex
+	var ids []uint16
+
+	go func() {
+		for {
+			conn, err := listener.Accept(context.Background())
+			if err != nil {
+				return
+			}
+
+			stream, err := conn.AcceptStream(context.Background())
+			if err != nil {
+				continue
+			}
+
+			// Each message is prefixed with a two-octet length field.
+			var length uint16
+			if err := binary.Read(stream, binary.BigEndian, &length); err != nil {
+				continue
+			}
+
+			query := make([]byte, length)
+			if _, err := io.ReadFull(stream, query); err != nil {
+				continue
+			}
+
+			r := new(dns.Msg)
+			if err := r.Unpack(query); err != nil {
+				continue
+			}
+
+			mu.Lock()
+			ids = append(ids, r.Id)
+			mu.Unlock()
+
+			packed, err := testReply(r).Pack()
+			if err != nil {
+				continue
+			}
+
+			response := make([]byte, 2+len(packed))
+			binary.BigEndian.PutUint16(response, uint16(len(packed))) // #nosec G115
+			copy(response[2:], packed)
+
+			_, _ = stream.Write(response)
+			_ = stream.Close()
+		}
+	}()
+
+	return listener.Addr().String(), func() []uint16 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uint16(nil), ids...)
+	}
+}
+
+func TestExchangeDoQ(t *testing.T) {
+
+	cert, caFile := newTestCertificate(t)
+	address, receivedIDs := startTestDoQServer(t, cert)
+
+	tlsConfig, err := NewTLSConfig(caFile, false)
+	if err != nil {
+		t.Fatalf("failed to create TLS config: %v", err)
+	}
+
+	msg := testQuery()
+	result, err := exchange(msg, doqScheme+address, QueryOptions{
+		Timeout:       5 * time.Second,
+		TLSConfig:     tlsConfig,
+		TLSServerName: testServerName,
+	})
+	if err != nil {
+		t.Fatalf("DoQ exchange failed: %v", err)
+	}
+
+	if result.transport != TransportQUIC {
+		t.Errorf("got transport %q, want %q", result.transport, TransportQUIC)
+	}
+
+	// RFC 9250 requires that the message ID is zero on the wire. The original
+	// message ID is restored in the response.
+	if ids := receivedIDs(); len(ids) != 1 || ids[0] != 0 {
+		t.Errorf("got query message IDs %#x, want [0x0]", ids)
+	}
+
+	if result.response.Id != msg.Id {
+		t.Errorf("got response message ID %#x, want %#x", result.response.Id, msg.Id)
+	}
+
+	if msg.Id != 0xbeef {
+		t.Errorf("submitted message ID was modified to %#x", msg.Id)
+	}
+
+	if len(result.response.Answer) != 1 {
+		t.Fatalf("got %d answer records, want 1", len(result.response.Answer))
+	}
+
+	a, ok := result.response.Answer[0].(*dns.A)
+	if !ok || a.A.String() != testAnswer {
+		t.Errorf("got answer %v, want A %s", result.response.Answer[0], testAnswer)
+	}
+}
+
+func TestIsDoQServer(t *testing.T) {
+
+	tests := []struct {
+		server string
+		want   bool
+	}{
+		{server: "quic://dns.example.com", want: true},
+		{server: "QUIC://192.0.2.1:853", want: true},
+		{server: "192.0.2.1", want: false},
+		{server: "tls://dns.example.com", want: false},
+		{server: "https://dns.example.com/dns-query", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isDoQServer(tt.server); got != tt.want {
+			t.Errorf("isDoQServer(%q) = %t, want %t", tt.server, got, tt.want)
+		}
+	}
+}
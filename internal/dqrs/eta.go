@@ -0,0 +1,202 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/miekg/dns"
+)
+
+// outdated indicates whether the DNS server provided an outdated answer. If
+// expected values were provided for the requested record type, an answer
+// which does not match the expected values is outdated. Otherwise, an answer
+// which differs from the answer provided by the majority of DNS servers is
+// considered outdated. Queries which did not provide an answer (e.g., the
+// query failed) are not considered outdated as the answer is unknown.
+func (dqr DNSQueryResponse) outdated(e Expectations, consensus Consensus) bool {
+
+	if !dqr.answered() {
+		return false
+	}
+
+	if result := e.Check(dqr); result.Status != "" {
+		return result.Status == ExpectationMismatch
+	}
+
+	return consensus.Result(dqr).Outlier
+}
+
+// remainingTTL returns the amount of time until the answer provided by the
+// DNS server expires from its cache. This is the highest TTL of the records
+// of the requested type in the answer section (or of all records in the
+// answer section if none are of the requested type, e.g., a CNAME without
+// the target record) so that other records provided with the answer, such as
+// CNAME records with a shorter TTL, do not result in an early estimate. For
+// negative answers (e.g., NXDOMAIN), this is the negative caching TTL from
+// the SOA record in the authority section. False is returned if the
+// remaining TTL cannot be determined.
+func (dqr DNSQueryResponse) remainingTTL() (time.Duration, bool) {
+
+	var ttl, requestedTTL uint32
+	var found, foundRequested bool
+	for _, rr := range dqr.Answer {
+		ttl, found = max(ttl, rr.Header().Ttl), true
+		if rr.Header().Rrtype == dqr.RequestedRecordType {
+			requestedTTL, foundRequested = max(requestedTTL, rr.Header().Ttl), true
+		}
+	}
+
+	if foundRequested {
+		ttl = requestedTTL
+	}
+
+	if !found {
+		for _, rr := range dqr.Authority {
+			soa, ok := rr.(*dns.SOA)
+			if !ok {
+				continue
+			}
+
+			// Per RFC 2308, negative answers are cached for the lower of the
+			// SOA record TTL and the SOA MINIMUM field.
+			ttl, found = min(soa.Hdr.Ttl, soa.Minttl), true
+			break
+		}
+	}
+
+	return time.Duration(ttl) * time.Second, found
+}
+
+// PrintPropagationETA lists the expected refresh time for each DNS server
+// which provided an outdated answer based on the remaining TTL of that
+// answer along with the latest time by which all queried DNS servers should
+// have converged. This is written to the supplementary output (see
+// SummaryOptions.auxOutput).
+func (dqrs DNSQueryResponses) PrintPropagationETA(e Expectations, opts SummaryOptions) {
+
+	if err := dqrs.writePropagationETA(opts.auxOutput(), e, time.Now()); err != nil {
+		log.Errorf("Error generating propagation estimate: %v", err)
+	}
+}
+
+// writePropagationETA writes the expected refresh time for each DNS server
+// which provided an outdated answer, relative to the given time, to the given
+// writer as a table with aligned columns. A note is included if any outdated
+// answers were identified using the majority answer instead of expected
+// values.
+func (dqrs DNSQueryResponses) writePropagationETA(output io.Writer, e Expectations, now time.Time) error {
+
+	w := newTableWriter(output)
+
+	_, _ = fmt.Fprintf(w, "\n\nPropagation estimate:\n\n")
+
+	consensus := dqrs.Consensus()
+
+	var outdated DNSQueryResponses
+	for _, item := range dqrs {
+		if item.outdated(e, consensus) {
+			outdated = append(outdated, item)
+		}
+	}
+
+	if len(outdated) == 0 {
+		_, _ = fmt.Fprintln(w, "No DNS servers provided an outdated answer")
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("error flushing tabwriter: %w", err)
+		}
+		return nil
+	}
+
+	columns := []string{"Server", "Query", "Type", "Answer", "Remaining TTL", "Expected refresh"}
+	writeTableHeader(w, columns)
+
+	var latest time.Duration
+	var unknown, byMajority int
+	for _, item := range outdated {
+		if _, ok := e[item.RequestedRecordType]; !ok {
+			byMajority++
+		}
+
+		remaining := "unknown"
+		refresh := "unknown"
+
+		ttl, ok := item.remainingTTL()
+		switch {
+		case item.Flags.Authoritative:
+			// Authoritative DNS servers do not cache answers; an outdated
+			// answer is refreshed when the zone is updated on that server.
+			remaining = "n/a"
+			refresh = "on zone update (authoritative)"
+			unknown++
+		case !ok:
+			unknown++
+		default:
+			remaining = ttl.String()
+			refresh = now.Add(ttl).Format(time.TimeOnly)
+			latest = max(latest, ttl)
+		}
+
+		writeSummaryRow(w, columns, []string{
+			item.ServerName(),
+			item.Query,
+			RecordType(item.RequestedRecordType),
+			strings.ReplaceAll(item.answerSet(), "\n", ", "),
+			remaining,
+			refresh,
+		})
+	}
+
+	_, _ = fmt.Fprintln(w)
+
+	if unknown < len(outdated) {
+		// The estimate only applies to all queried DNS servers if the
+		// expected refresh time is known for every outdated answer.
+		subject := "All queried DNS servers"
+		if unknown > 0 {
+			subject = "DNS servers with a known refresh time"
+		}
+
+		_, _ = fmt.Fprintf(
+			w,
+			"%s should converge by %s (in %v)\n",
+			subject,
+			now.Add(latest).Format(time.TimeOnly),
+			latest,
+		)
+	}
+
+	if unknown > 0 {
+		_, _ = fmt.Fprintf(
+			w,
+			"%d outdated answers were not included in this estimate as their expected refresh time is unknown\n",
+			unknown,
+		)
+	}
+
+	if byMajority > 0 {
+		// Early in a change the majority of DNS servers may still provide
+		// the previous answer, in which case the DNS servers listed here
+		// are the ones which have already picked up the change.
+		_, _ = fmt.Fprintf(
+			w,
+			"%d outdated answers were identified using the majority answer as expected values were not provided; this estimate assumes that the majority answer is current\n",
+			byMajority,
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error flushing tabwriter: %w", err)
+	}
+
+	return nil
+}
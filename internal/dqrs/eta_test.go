@@ -0,0 +1,221 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testCNAME returns a CNAME record for www.example.com with the given TTL.
+func testCNAME(ttl uint32) dns.RR {
+	return &dns.CNAME{
+		Hdr: dns.RR_Header{
+			Name:   "www.example.com.",
+			Rrtype: dns.TypeCNAME,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Target: "web.example.com.",
+	}
+}
+
+// testSOA returns an SOA record for example.com with the given TTL and
+// MINIMUM field.
+func testSOA(ttl uint32, minttl uint32) dns.RR {
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   "example.com.",
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    ttl,
+		},
+		Ns:     "ns1.example.com.",
+		Mbox:   "hostmaster.example.com.",
+		Serial: 2021010101,
+		Minttl: minttl,
+	}
+}
+
+func TestRemainingTTL(t *testing.T) {
+
+	// A CNAME record with a short TTL followed by the A record it points to.
+	aliased := withTTL(testResponse("s1", "192.0.2.1"), time.Now(), 600)
+	aliased.Answer = append([]dns.RR{testCNAME(30)}, aliased.Answer...)
+
+	// A CNAME record without the A record it points to.
+	cnameOnly := testResponse("s1")
+	cnameOnly.Answer = []dns.RR{testCNAME(30)}
+
+	negative := func(ttl uint32, minttl uint32) DNSQueryResponse {
+		dqr := testResponse("s1")
+		dqr.Rcode = dns.RcodeNameError
+		dqr.Authority = []dns.RR{testSOA(ttl, minttl)}
+		return dqr
+	}
+
+	tests := []struct {
+		name      string
+		dqr       DNSQueryResponse
+		want      time.Duration
+		wantKnown bool
+	}{
+		{
+			name:      "requested type",
+			dqr:       testResponse("s1", "192.0.2.1", "192.0.2.2"),
+			want:      300 * time.Second,
+			wantKnown: true,
+		},
+		{
+			name:      "requested type preferred over CNAME",
+			dqr:       aliased,
+			want:      600 * time.Second,
+			wantKnown: true,
+		},
+		{
+			name:      "CNAME only",
+			dqr:       cnameOnly,
+			want:      30 * time.Second,
+			wantKnown: true,
+		},
+		{
+			name:      "negative answer uses SOA MINIMUM",
+			dqr:       negative(3600, 300),
+			want:      300 * time.Second,
+			wantKnown: true,
+		},
+		{
+			name:      "negative answer uses SOA TTL",
+			dqr:       negative(60, 300),
+			want:      60 * time.Second,
+			wantKnown: true,
+		},
+		{
+			name:      "unknown",
+			dqr:       testResponse("s1"),
+			wantKnown: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, known := tt.dqr.remainingTTL()
+			if known != tt.wantKnown {
+				t.Fatalf("got known %t, want %t", known, tt.wantKnown)
+			}
+
+			if got != tt.want {
+				t.Errorf("got remaining TTL %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWritePropagationETA(t *testing.T) {
+
+	now := time.Date(2021, 6, 1, 14, 0, 0, 0, time.UTC)
+
+	expectations, err := NewExpectations(map[string][]string{"A": {"192.0.2.2"}})
+	if err != nil {
+		t.Fatalf("failed to create expectations: %v", err)
+	}
+
+	authoritative := testResponse("s3", "192.0.2.1")
+	authoritative.Flags.Authoritative = true
+
+	tests := []struct {
+		name        string
+		responses   DNSQueryResponses
+		e           Expectations
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name: "all converged",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.2"),
+				testResponse("s2", "192.0.2.2"),
+			},
+			e:    expectations,
+			want: []string{"No DNS servers provided an outdated answer"},
+		},
+		{
+			name: "all refresh times known",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.2"),
+				withTTL(testResponse("s2", "192.0.2.1"), now, 120),
+			},
+			e: expectations,
+			want: []string{
+				"2m0s",
+				"14:02:00",
+				"All queried DNS servers should converge by 14:02:00 (in 2m0s)",
+			},
+			wantMissing: []string{
+				"were not included",
+				"majority answer",
+			},
+		},
+		{
+			name: "authoritative answer excluded",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.2"),
+				withTTL(testResponse("s2", "192.0.2.1"), now, 120),
+				authoritative,
+			},
+			e: expectations,
+			want: []string{
+				"n/a",
+				"on zone update (authoritative)",
+				"DNS servers with a known refresh time should converge by 14:02:00 (in 2m0s)",
+				"1 outdated answers were not included in this estimate",
+			},
+			wantMissing: []string{
+				"All queried DNS servers",
+			},
+		},
+		{
+			name: "majority answer without expectations",
+			responses: DNSQueryResponses{
+				testResponse("s1", "192.0.2.1"),
+				testResponse("s2", "192.0.2.1"),
+				testResponse("s3", "192.0.2.2"),
+			},
+			want: []string{
+				"s3",
+				"1 outdated answers were identified using the majority answer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.responses.writePropagationETA(&buf, tt.e, now); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			output := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output does not contain %q:\n%s", want, output)
+				}
+			}
+
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("output unexpectedly contains %q:\n%s", unwanted, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/miekg/dns"
+)
+
+// Outcomes of checking the answer provided by a DNS server against the
+// expected values.
+const (
+	ExpectationPass     string = "PASS"
+	ExpectationMismatch string = "FAIL"
+	ExpectationError    string = "ERROR"
+)
+
+// Expectations is the collection of expected values for each record type.
+// Values are stored in normalized presentation format so that they may be
+// compared against the records returned by a DNS server.
+type Expectations map[uint16][]string
+
+// ExpectationResult is the outcome of checking the answer provided by a DNS
+// server against the expected values for the requested record type.
+type ExpectationResult struct {
+
+	// Status is the outcome of the check; one of ExpectationPass,
+	// ExpectationMismatch or ExpectationError. This is empty if no expected
+	// values were provided for the requested record type.
+	Status string
+
+	// Expected is the list of expected values.
+	Expected []string
+
+	// Actual is the list of values returned by the DNS server for the
+	// requested record type.
+	Actual []string
+}
+
+// ExpectationSummary is the number of query responses for each outcome of
+// checking answers against expected values.
+type ExpectationSummary struct {
+	Passed     int
+	Mismatched int
+	Errors     int
+}
+
+// NewExpectations creates a collection of expected values from the given
+// values keyed by record type (e.g., A, MX). Each value is parsed as record
+// data for the record type. An error is returned if a record type is not
+// supported or a value is not valid for the record type.
+func NewExpectations(expected map[string][]string) (Expectations, error) {
+
+	expectations := make(Expectations, len(expected))
+	for typeString, values := range expected {
+		rrType, err := RRStringToType(typeString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record type %q provided for expected value: %w", typeString, err)
+		}
+
+		if !QueryableType(rrType) {
+			return nil, fmt.Errorf("invalid record type %q provided for expected value: type not supported for queries", typeString)
+		}
+
+		for _, value := range values {
+			rr, err := dns.NewRR(fmt.Sprintf(". 0 IN %s %s", RecordType(rrType), value))
+			if err != nil || rr == nil {
+				return nil, fmt.Errorf("invalid expected value %q provided for record type %s: %v", value, RecordType(rrType), err)
+			}
+
+			expectations[rrType] = append(expectations[rrType], normalizeRecordValue(RecordValue(rr)))
+		}
+
+		sort.Strings(expectations[rrType])
+	}
+
+	return expectations, nil
+}
+
+// Check compares the records returned in the answer section of the query
+// response for the requested record type against the expected values for
+// that record type. Records are compared without regard to order, case or
+// TTL. Records of other types (e.g., a CNAME record returned along with the
+// requested A records) are ignored.
+func (e Expectations) Check(dqr DNSQueryResponse) ExpectationResult {
+
+	expected, ok := e[dqr.RequestedRecordType]
+	if !ok {
+		return ExpectationResult{}
+	}
+
+	result := ExpectationResult{
+		Expected: expected,
+	}
+
+	recordType := RecordType(dqr.RequestedRecordType)
+	for _, record := range dqr.SectionRecords(SectionAnswer) {
+		if record.Type == recordType {
+			result.Actual = append(result.Actual, normalizeRecordValue(record.Value))
+		}
+	}
+	sort.Strings(result.Actual)
+
+	// A missing name or record type is an answer which does not match the
+	// expected values, but a failure to obtain an answer is not.
+	switch {
+	case dqr.QueryError != nil && !errors.Is(dqr.QueryError, ErrNoRecordsFound):
+		result.Status = ExpectationError
+	case strings.Join(result.Actual, "\n") == strings.Join(expected, "\n"):
+		result.Status = ExpectationPass
+	default:
+		result.Status = ExpectationMismatch
+	}
+
+	return result
+}
+
+// CheckExpectations checks the answer provided by each DNS server against
+// the expected values and returns the number of query responses for each
+// outcome. Query responses for record types without expected values are not
+// counted.
+func (dqrs DNSQueryResponses) CheckExpectations(e Expectations) ExpectationSummary {
+
+	var summary ExpectationSummary
+	for _, item := range dqrs {
+		switch e.Check(item).Status {
+		case ExpectationPass:
+			summary.Passed++
+		case ExpectationMismatch:
+			summary.Mismatched++
+		case ExpectationError:
+			summary.Errors++
+		}
+	}
+
+	return summary
+}
+
+// PrintExpectations lists whether the answer provided by each DNS server
+// matches the expected values. This is written to the supplementary output
+// (see SummaryOptions.auxOutput).
+func (dqrs DNSQueryResponses) PrintExpectations(e Expectations, opts SummaryOptions) {
+
+	if err := dqrs.writeExpectations(opts.auxOutput(), e); err != nil {
+		log.Errorf("Error generating expected values summary: %v", err)
+	}
+}
+
+// writeExpectations writes whether the answer provided by each DNS server
+// matches the expected values to the given writer as a table with aligned
+// columns.
+func (dqrs DNSQueryResponses) writeExpectations(output io.Writer, e Expectations) error {
+
+	w := newTableWriter(output)
+
+	_, _ = fmt.Fprintf(w, "\n\nExpected values:\n\n")
+
+	columns := []string{"Server", "Query", "Type", "Result", "Expected", "Actual"}
+	writeTableHeader(w, columns)
+
+	for _, item := range dqrs {
+		result := e.Check(item)
+		if result.Status == "" {
+			continue
+		}
+
+		actual := strings.Join(result.Actual, ", ")
+		switch {
+		case item.QueryError != nil:
+			actual = item.QueryError.Error()
+		case actual == "":
+			actual = item.answerSet()
+		}
+
+		writeSummaryRow(w, columns, []string{
+			item.ServerName(),
+			item.Query,
+			RecordType(item.RequestedRecordType),
+			result.Status,
+			strings.Join(result.Expected, ", "),
+			actual,
+		})
+	}
+
+	summary := dqrs.CheckExpectations(e)
+	_, _ = fmt.Fprintf(
+		w,
+		"\n%d passed, %d failed, %d errors\n",
+		summary.Passed,
+		summary.Mismatched,
+		summary.Errors,
+	)
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("error flushing tabwriter: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeRecordValue returns the given record value in a form which may be
+// compared against other record values without regard to case.
+func normalizeRecordValue(value string) string {
+	return strings.ToLower(value)
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// HeaderFlags is the collection of header flags set in a response returned
+// by a DNS server.
+type HeaderFlags struct {
+
+	// Authoritative (AA) indicates that the responding DNS server is an
+	// authority for the queried name.
+	Authoritative bool
+
+	// Truncated (TC) indicates that the response was truncated.
+	Truncated bool
+
+	// RecursionDesired (RD) is copied from the query and indicates that
+	// recursion was requested.
+	RecursionDesired bool
+
+	// RecursionAvailable (RA) indicates that the responding DNS server
+	// supports recursive queries.
+	RecursionAvailable bool
+
+	// AuthenticatedData (AD) indicates that the responding DNS server
+	// validated all records in the response using DNSSEC.
+	AuthenticatedData bool
+
+	// CheckingDisabled (CD) is copied from the query and indicates that
+	// DNSSEC validation was disabled.
+	CheckingDisabled bool
+}
+
+// newHeaderFlags records the header flags from the given DNS message header.
+func newHeaderFlags(hdr dns.MsgHdr) HeaderFlags {
+	return HeaderFlags{
+		Authoritative:      hdr.Authoritative,
+		Truncated:          hdr.Truncated,
+		RecursionDesired:   hdr.RecursionDesired,
+		RecursionAvailable: hdr.RecursionAvailable,
+		AuthenticatedData:  hdr.AuthenticatedData,
+		CheckingDisabled:   hdr.CheckingDisabled,
+	}
+}
+
+// String returns the set header flags as a space-separated list of
+// lowercase flag names (e.g., "aa rd ra") using the same notation as the dig
+// utility.
+func (hf HeaderFlags) String() string {
+
+	flags := make([]string, 0, 6)
+
+	if hf.Authoritative {
+		flags = append(flags, "aa")
+	}
+	if hf.Truncated {
+		flags = append(flags, "tc")
+	}
+	if hf.RecursionDesired {
+		flags = append(flags, "rd")
+	}
+	if hf.RecursionAvailable {
+		flags = append(flags, "ra")
+	}
+	if hf.AuthenticatedData {
+		flags = append(flags, "ad")
+	}
+	if hf.CheckingDisabled {
+		flags = append(flags, "cd")
+	}
+
+	return strings.Join(flags, " ")
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// htmlReportTmpl is the template used to generate a standalone HTML report.
+// Rows for DNS servers which provided an answer which differs from the
+// majority of DNS servers are highlighted.
+const htmlReportTmpl string = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+tr.outlier td { background: #fde2e1; font-weight: bold; }
+tr.changed td { font-style: italic; }
+dt { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<dl>
+{{- if .Report.Generated }}
+<dt>Generated</dt><dd>{{ .Report.Generated }}</dd>
+{{- end }}
+<dt>Queries</dt><dd>{{ range $i, $v := .Report.Queries }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</dd>
+<dt>Servers</dt><dd>{{ range $i, $v := .Report.Servers }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</dd>
+<dt>Types</dt><dd>{{ range $i, $v := .Report.Types }}{{ if $i }}, {{ end }}<code>{{ $v }}</code>{{ end }}</dd>
+</dl>
+<h2>Results</h2>
+<table>
+<thead>
+<tr>{{ range .Report.Columns }}<th>{{ . }}</th>{{ end }}</tr>
+</thead>
+<tbody>
+{{- range .Report.Rows }}
+<tr{{ with .Class }} class="{{ . }}"{{ end }}>{{ range .Cells }}<td>{{ . }}</td>{{ end }}</tr>
+{{- end }}
+</tbody>
+</table>
+{{- if .Report.Notes }}
+<ul>
+{{- range .Report.Notes }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+</body>
+</html>
+`
+
+// writeHTML writes a shareable report of all collected DNS query results to
+// the given writer as a standalone HTML document.
+func (dqrs DNSQueryResponses) writeHTML(w io.Writer, opts SummaryOptions) error {
+
+	tmpl, err := template.New("report").Parse(htmlReportTmpl)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML report template: %w", err)
+	}
+
+	data := struct {
+		Title  string
+		Report report
+	}{
+		Title:  reportTitle,
+		Report: dqrs.newReport(opts),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to generate HTML report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// jsonSchemaVersion is the version of the JSON results document schema. This
+// is incremented for any change which is not backwards compatible.
+const jsonSchemaVersion int = 1
+
+// jsonReport is the JSON results document.
+type jsonReport struct {
+	SchemaVersion int          `json:"schema_version"`
+	Timestamp     string       `json:"timestamp,omitempty"`
+	Results       []jsonResult `json:"results"`
+}
+
+// jsonResult is the JSON representation of a single DNS query and response.
+type jsonResult struct {
+	Server         string        `json:"server"`
+	ServerLabel    string        `json:"server_label,omitempty"`
+	ServerHostname string        `json:"server_hostname,omitempty"`
+	Query          string        `json:"query"`
+	Type           string        `json:"type"`
+	Transport      string        `json:"transport"`
+	RTTMs          float64       `json:"rtt_ms"`
+	Attempts       int           `json:"attempts"`
+	Retried        bool          `json:"retried"`
+	PacingDelayMs  float64       `json:"pacing_delay_ms"`
+	Responded      bool          `json:"responded"`
+	Rcode          string        `json:"rcode,omitempty"`
+	Flags          *jsonFlags    `json:"flags,omitempty"`
+	HTTPStatus     int           `json:"http_status,omitempty"`
+	Error          string        `json:"error,omitempty"`
+	Changed        bool          `json:"changed"`
+	Consensus      jsonConsensus `json:"consensus"`
+	Records        []jsonRecord  `json:"records"`
+}
+
+// jsonConsensus is the JSON representation of the outcome of comparing the
+// answer provided by a DNS server against the majority answer.
+type jsonConsensus struct {
+	Answered        bool   `json:"answered"`
+	HasMajority     bool   `json:"has_majority"`
+	Outlier         bool   `json:"outlier"`
+	MajorityServers int    `json:"majority_servers"`
+	TotalServers    int    `json:"total_servers"`
+	Note            string `json:"note,omitempty"`
+}
+
+// jsonFlags is the JSON representation of the header flags set in a
+// response.
+type jsonFlags struct {
+	Authoritative      bool `json:"aa"`
+	Truncated          bool `json:"tc"`
+	RecursionDesired   bool `json:"rd"`
+	RecursionAvailable bool `json:"ra"`
+	AuthenticatedData  bool `json:"ad"`
+	CheckingDisabled   bool `json:"cd"`
+}
+
+// jsonRecord is the JSON representation of a record returned as part of a
+// query response.
+type jsonRecord struct {
+	Section string         `json:"section"`
+	Type    string         `json:"type"`
+	TTL     uint32         `json:"ttl"`
+	Value   string         `json:"value"`
+	Fields  map[string]any `json:"fields"`
+}
+
+// writeJSON writes all collected DNS query results as a single JSON document
+// to the given writer. Records from all sections of each response are
+// included regardless of the requested summary settings.
+func (dqrs DNSQueryResponses) writeJSON(w io.Writer, opts SummaryOptions) error {
+
+	report := jsonReport{
+		SchemaVersion: jsonSchemaVersion,
+		Results:       make([]jsonResult, 0, len(dqrs)),
+	}
+
+	if !opts.OmitTimestamp {
+		report.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	consensus := dqrs.Consensus()
+	changed := dqrs.Changed(opts.Previous)
+	for _, item := range dqrs {
+		report.Results = append(
+			report.Results,
+			item.jsonResult(consensus.Result(item), changed[item.ResponseKey()]),
+		)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON results: %w", err)
+	}
+
+	return nil
+}
+
+// jsonResult converts the DNS query response to its JSON representation
+// along with the given consensus outcome and whether the response changed
+// since the previous run.
+func (dqr DNSQueryResponse) jsonResult(consensus ConsensusResult, changed bool) jsonResult {
+
+	requestType, err := RRTypeToString(dqr.RequestedRecordType)
+	if err != nil {
+		requestType = ""
+	}
+
+	result := jsonResult{
+		Server:         dqr.Server,
+		ServerLabel:    dqr.ServerLabel,
+		ServerHostname: dqr.ServerHostname,
+		Query:          dqr.Query,
+		Type:           requestType,
+		Transport:      dqr.Transport,
+		RTTMs:          durationToMilliseconds(dqr.ResponseTime),
+		Attempts:       dqr.Attempts,
+		Retried:        dqr.Retried(),
+		PacingDelayMs:  durationToMilliseconds(dqr.PacingDelay),
+		Responded:      dqr.Responded,
+		Rcode:          dqr.RcodeString(),
+		HTTPStatus:     dqr.HTTPStatus,
+		Changed:        changed,
+		Consensus: jsonConsensus{
+			Answered:        consensus.Answered,
+			HasMajority:     consensus.HasMajority,
+			Outlier:         consensus.Outlier,
+			MajorityServers: consensus.MajorityServers,
+			TotalServers:    consensus.TotalServers,
+			Note:            consensus.Note(),
+		},
+		Records: make([]jsonRecord, 0, len(dqr.Answer)),
+	}
+
+	if dqr.QueryError != nil {
+		result.Error = dqr.QueryError.Error()
+	}
+
+	if dqr.Responded {
+		result.Flags = &jsonFlags{
+			Authoritative:      dqr.Flags.Authoritative,
+			Truncated:          dqr.Flags.Truncated,
+			RecursionDesired:   dqr.Flags.RecursionDesired,
+			RecursionAvailable: dqr.Flags.RecursionAvailable,
+			AuthenticatedData:  dqr.Flags.AuthenticatedData,
+			CheckingDisabled:   dqr.Flags.CheckingDisabled,
+		}
+	}
+
+	// Sort records so that output is consistent between runs
+	dqr.SortRecordsAsc()
+
+	for _, record := range dqr.Records() {
+		result.Records = append(result.Records, jsonRecord{
+			Section: record.Section,
+			Type:    record.Type,
+			TTL:     record.TTL,
+			Value:   record.Value,
+			Fields:  record.Fields,
+		})
+	}
+
+	return result
+}
+
+// durationToMilliseconds converts the given duration to a fractional number
+// of milliseconds.
+func durationToMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
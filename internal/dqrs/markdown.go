@@ -0,0 +1,95 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownCellReplacer escapes characters with special meaning within
+// Markdown table cells.
+var markdownCellReplacer = strings.NewReplacer(
+	"|", `\|`,
+	"\n", " ",
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"~", `\~`,
+)
+
+// writeMarkdown writes a shareable report of all collected DNS query results
+// to the given writer as a Markdown document. Rows for DNS servers which
+// provided an answer which differs from the majority of DNS servers are
+// highlighted in bold and DNS servers whose results changed since the
+// previous run are flagged with a marker.
+func (dqrs DNSQueryResponses) writeMarkdown(w io.Writer, opts SummaryOptions) error {
+
+	r := dqrs.newReport(opts)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", reportTitle)
+
+	if r.Generated != "" {
+		fmt.Fprintf(&b, "- **Generated:** %s\n", r.Generated)
+	}
+	fmt.Fprintf(&b, "- **Queries:** %s\n", markdownList(r.Queries))
+	fmt.Fprintf(&b, "- **Servers:** %s\n", markdownList(r.Servers))
+	fmt.Fprintf(&b, "- **Types:** %s\n\n", markdownList(r.Types))
+
+	fmt.Fprintf(&b, "## Results\n\n")
+
+	b.WriteString("|")
+	for _, column := range r.Columns {
+		fmt.Fprintf(&b, " %s |", column)
+	}
+	b.WriteString("\n|")
+	for range r.Columns {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, row := range r.Rows {
+		b.WriteString("|")
+		for _, cell := range row.Cells {
+			cell = markdownCellReplacer.Replace(cell)
+			if row.Outlier && cell != "" {
+				cell = "**" + cell + "**"
+			}
+			fmt.Fprintf(&b, " %s |", cell)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Notes) > 0 {
+		b.WriteString("\n")
+		for _, note := range r.Notes {
+			fmt.Fprintf(&b, "- %s\n", markdownCellReplacer.Replace(note))
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+
+	return nil
+}
+
+// markdownList formats the given values as a comma-separated list of inline
+// code spans.
+func markdownList(values []string) string {
+
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		items = append(items, "`"+value+"`")
+	}
+
+	return strings.Join(items, ", ")
+}
@@ -22,6 +22,27 @@ import (
 // specified query.
 var ErrNoRecordsFound = errors.New("no records found for query")
 
+// ErrNXDomain indicates that the nameserver reported that the queried name
+// does not exist (NXDOMAIN).
+var ErrNXDomain = fmt.Errorf("%w: name does not exist (NXDOMAIN)", ErrNoRecordsFound)
+
+// ErrNoData indicates that the queried name exists, but the nameserver has no
+// records of the requested type for it (NODATA).
+var ErrNoData = fmt.Errorf("%w: no records of requested type (NODATA)", ErrNoRecordsFound)
+
+// ErrServFail indicates that the nameserver was unable to process the query
+// (SERVFAIL). This commonly indicates a problem with the nameserver or with
+// the authoritative nameservers for the queried name.
+var ErrServFail = errors.New("server failed to complete query (SERVFAIL)")
+
+// ErrRefused indicates that the nameserver refused to process the query
+// (REFUSED).
+var ErrRefused = errors.New("server refused query (REFUSED)")
+
+// ErrUnexpectedRcode indicates that the nameserver returned a response code
+// other than those with a specific error.
+var ErrUnexpectedRcode = errors.New("unexpected response code")
+
 // DNSQueryResponse represents a query and response from a DNS server.
 // Multiple records may be returned for a single query (e.g., CNAME and A
 // records).
@@ -30,6 +51,21 @@ type DNSQueryResponse struct {
 	// Server is the DNS server used for this query and response.
 	Server string
 
+	// ServerLabel is an optional friendly name for the DNS server used for
+	// this query and response.
+	ServerLabel string
+
+	// ServerID uniquely identifies the DNS server entry used for this query
+	// and response. This differs from Server if the same address was
+	// specified more than once using different transports or labels. If not
+	// set, Server is used to identify the DNS server.
+	ServerID string
+
+	// ServerHostname is the hostname originally specified for the DNS server
+	// used for this query and response. This is only set if the server was
+	// resolved from a hostname to the IP Address recorded in Server.
+	ServerHostname string
+
 	// Query is the FQDN that we requested a record for.
 	Query string
 
@@ -42,6 +78,15 @@ type DNSQueryResponse struct {
 	// needed.
 	Answer []dns.RR
 
+	// Authority is the collection of records from the authority section of
+	// the response, such as the SOA record provided with a negative answer
+	// or the NS records provided with a referral.
+	Authority []dns.RR
+
+	// Additional is the collection of records from the additional section
+	// of the response, such as glue records provided with a referral.
+	Additional []dns.RR
+
 	// ResponseTime, also known as the Round-trip Time, can be best summed up
 	// by this Cloudflare definition: "Round-trip time (RTT) is the duration
 	// in milliseconds (ms) it takes for a network request to go from a
@@ -51,6 +96,41 @@ type DNSQueryResponse struct {
 	// RequestedRecordType represents the type of record requested as part of
 	// the query
 	RequestedRecordType uint16
+
+	// Transport is the network transport (e.g., udp, tcp) which produced
+	// the response. This may differ from the requested transport if a
+	// truncated UDP response was retried using TCP.
+	Transport string
+
+	// HTTPStatus is the HTTP status code returned by the DNS server when
+	// using DNS-over-HTTPS (DoH). This is zero for other transports.
+	HTTPStatus int
+
+	// PacingDelay is the amount of time that submission of the query was
+	// delayed in order to honor configured rate limits. This is zero if the
+	// query was submitted without delay.
+	PacingDelay time.Duration
+
+	// Attempts is the number of times the query was submitted to the DNS
+	// server. This is greater than one if the query was retried.
+	Attempts int
+
+	// Responded indicates whether the DNS server returned a response to the
+	// query. The response code and header flags are only meaningful if this
+	// is true.
+	Responded bool
+
+	// Rcode is the response code (e.g., NOERROR, NXDOMAIN) returned by the
+	// DNS server.
+	Rcode int
+
+	// Flags is the collection of header flags set in the response returned
+	// by the DNS server.
+	Flags HeaderFlags
+
+	// ReceivedAt is the time at which the response was received from the
+	// DNS server. This is the zero value if the DNS server did not respond.
+	ReceivedAt time.Time
 }
 
 // DNSQueryResponses is a collection of DNS query responses. Intended for
@@ -63,6 +143,40 @@ func (dqr DNSQueryResponse) Error() string {
 	return fmt.Sprintf("%v", dqr.QueryError)
 }
 
+// ServerName returns the friendly label for the DNS server used for this
+// query if provided, otherwise the server address. If the server was
+// resolved from a hostname, the label (or hostname if a label was not
+// provided) is returned along with the server address.
+func (dqr DNSQueryResponse) ServerName() string {
+
+	name := dqr.ServerLabel
+	if name == "" {
+		name = dqr.ServerHostname
+	}
+
+	switch {
+	case name == "":
+		return dqr.Server
+	case dqr.ServerHostname != "":
+		return fmt.Sprintf("%s (%s)", name, dqr.Server)
+	default:
+		return name
+	}
+}
+
+// Retried indicates whether the query was submitted more than once before
+// the DNS server responded or the query was abandoned.
+func (dqr DNSQueryResponse) Retried() bool {
+	return dqr.Attempts > 1
+}
+
+// succeededAfterRetry indicates whether the DNS server responded to the
+// query only after the query was retried. Any response counts, including
+// negative answers (e.g., NXDOMAIN).
+func (dqr DNSQueryResponse) succeededAfterRetry() bool {
+	return dqr.Retried() && dqr.Responded
+}
+
 // Less compares records and indicates whether the first argument is less than
 // the second argument. Preference is given to CNAME records.
 func (dqr *DNSQueryResponse) Less(i, j int) bool {
@@ -112,57 +226,51 @@ func (dqr *DNSQueryResponse) SortRecordsDesc() {
 
 }
 
-// Records returns all DNS records associated with a query response.
+// Records returns all DNS records associated with a query response. Records
+// from the answer section are listed first, followed by records from the
+// authority and additional sections. Each record is tagged with the section
+// of the response that it was returned in.
 func (dqr DNSQueryResponse) Records() []DNSRecord {
 
-	records := make([]DNSRecord, 0, len(dqr.Answer))
-
-	for _, record := range dqr.Answer {
-
-		var recordVal string
-		var recordType string
-
-		// FIXME: How to dynamically get a "short" string value for each
-		// record type so that we don't have to hard-code in a switch
-		// statement and then use a type-specific field or method to retrieve
-		// a text copy of the value? For example, *dns.CNAME type requires
-		// use of v.Target (field value) to get a usable string, whereas
-		// v.AAAA type has a usable String() method.
-
-		switch v := record.(type) {
-		case *dns.A:
-			recordVal = v.A.String()
-			recordType = RequestTypeA
-		case *dns.AAAA:
-			recordVal = v.AAAA.String()
-			recordType = RequestTypeAAAA
-		case *dns.CNAME:
-			recordVal = v.Target
-			recordType = RequestTypeCNAME
-		case *dns.MX:
-			recordVal = v.Mx
-			recordType = RequestTypeMX
-		case *dns.NS:
-			recordVal = v.Ns
-			recordType = RequestTypeNS
-		case *dns.PTR:
-			recordVal = v.Ptr
-			recordType = RequestTypePTR
-		case *dns.SRV:
-			recordVal = v.Target
-			recordType = RequestTypeSRV
-		default:
-			recordVal = recordValueUnknown
-			recordType = RequestTypeUnknown
+	records := make([]DNSRecord, 0, len(dqr.Answer)+len(dqr.Authority)+len(dqr.Additional))
+
+	sections := []struct {
+		name string
+		rrs  []dns.RR
+	}{
+		{name: SectionAnswer, rrs: dqr.Answer},
+		{name: SectionAuthority, rrs: dqr.Authority},
+		{name: SectionAdditional, rrs: dqr.Additional},
+	}
+
+	for _, section := range sections {
+		for _, record := range section.rrs {
+
+			// The OPT pseudo-record carries EDNS(0) settings and is not a
+			// record for the query.
+			if _, ok := record.(*dns.OPT); ok {
+				continue
+			}
+
+			records = append(records, newDNSRecord(record, section.name))
 		}
+	}
 
-		ttl := record.Header().Ttl
+	return records
+}
 
-		records = append(records, DNSRecord{
-			Value: recordVal,
-			Type:  recordType,
-			TTL:   ttl,
-		})
+// SectionRecords returns the DNS records associated with a query response
+// from the specified sections of the response.
+func (dqr DNSQueryResponse) SectionRecords(sections ...string) []DNSRecord {
+
+	var records []DNSRecord
+	for _, record := range dqr.Records() {
+		for _, section := range sections {
+			if record.Section == section {
+				records = append(records, record)
+				break
+			}
+		}
 	}
 
 	return records
@@ -187,9 +295,44 @@ func (dqrs DNSQueryResponses) RecordsFound() bool {
 
 }
 
+// Paced returns the number of queries which were delayed in order to honor
+// configured rate limits along with the longest delay applied to any one
+// query.
+func (dqrs DNSQueryResponses) Paced() (int, time.Duration) {
+
+	var count int
+	var longest time.Duration
+	for _, item := range dqrs {
+		if item.PacingDelay <= 0 {
+			continue
+		}
+
+		count++
+		if item.PacingDelay > longest {
+			longest = item.PacingDelay
+		}
+	}
+
+	return count, longest
+}
+
+// Retried returns the number of queries which the DNS server responded to
+// only after the query was retried.
+func (dqrs DNSQueryResponses) Retried() int {
+
+	var count int
+	for _, item := range dqrs {
+		if item.succeededAfterRetry() {
+			count++
+		}
+	}
+
+	return count
+}
+
 // PerformQuery wraps the bulk of the query/record logic performed by this
 // application
-func PerformQuery(query string, server string, qType uint16, timeout time.Duration) DNSQueryResponse {
+func PerformQuery(query string, server string, qType uint16, opts QueryOptions) DNSQueryResponse {
 
 	var msg dns.Msg
 
@@ -223,28 +366,32 @@ func PerformQuery(query string, server string, qType uint16, timeout time.Durati
 		RequestedRecordType: qType,
 	}
 
-	// construct client so that we are able to override default settings
-	client := dns.Client{
-		Net:     "udp",
-		Timeout: timeout,
-	}
-
-	// Perform UDP-based query using custom client settings
-	remoteAddress := net.JoinHostPort(server, defaultDNSPort)
-	in, rtt, err := client.Exchange(&msg, remoteAddress)
-	dnsQueryResponse.ResponseTime = rtt
+	// Perform query using the requested transport and custom client
+	// settings, retrying failed queries as requested
+	result, attempts, err := exchangeWithRetry(&msg, server, opts)
+	dnsQueryResponse.Attempts = attempts
+	dnsQueryResponse.ResponseTime = result.rtt
+	dnsQueryResponse.Transport = result.transport
+	dnsQueryResponse.HTTPStatus = result.httpStatus
 	if err != nil {
 		dnsQueryResponse.QueryError = err
 		return dnsQueryResponse
 	}
 
-	// Early exit if the DNS server returns an unexpected result
-	if len(in.Answer) < 1 {
-		dnsQueryResponse.QueryError = ErrNoRecordsFound
-		return dnsQueryResponse
-	}
+	in := result.response
+	dnsQueryResponse.Responded = true
+	dnsQueryResponse.ReceivedAt = time.Now()
+	dnsQueryResponse.Rcode = in.Rcode
+	dnsQueryResponse.Flags = newHeaderFlags(in.MsgHdr)
 
+	// Retain any answers provided alongside a negative response code (e.g.,
+	// a CNAME pointing to a name which does not exist).
 	dnsQueryResponse.Answer = in.Answer
+	dnsQueryResponse.Authority = in.Ns
+	dnsQueryResponse.Additional = in.Extra
+
+	// Record the specific error for any negative outcome
+	dnsQueryResponse.QueryError = rcodeError(in)
 
 	return dnsQueryResponse
 }
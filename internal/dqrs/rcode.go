@@ -0,0 +1,63 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// RcodeString returns the name of the response code returned by the DNS
+// server (e.g., NOERROR, NXDOMAIN) or an empty string if the DNS server did
+// not respond.
+func (dqr DNSQueryResponse) RcodeString() string {
+
+	if !dqr.Responded {
+		return ""
+	}
+
+	return rcodeToString(dqr.Rcode)
+}
+
+// rcodeToString returns the name of the given response code. A generic name
+// is returned for response codes not known to the dns package.
+func rcodeToString(rcode int) string {
+
+	name, ok := dns.RcodeToString[rcode]
+	if !ok {
+		return fmt.Sprintf("RCODE%d", rcode)
+	}
+
+	return name
+}
+
+// rcodeError returns the error corresponding to the negative outcome
+// indicated by the given response or nil if the response contains answers.
+func rcodeError(in *dns.Msg) error {
+
+	switch in.Rcode {
+	case dns.RcodeSuccess:
+		if len(in.Answer) < 1 {
+			return ErrNoData
+		}
+		return nil
+
+	case dns.RcodeNameError:
+		return ErrNXDomain
+
+	case dns.RcodeServerFailure:
+		return ErrServFail
+
+	case dns.RcodeRefused:
+		return ErrRefused
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnexpectedRcode, rcodeToString(in.Rcode))
+	}
+}
@@ -7,6 +7,8 @@
 
 package dqrs
 
+import "github.com/miekg/dns"
+
 // DNSRecord represents a record returned as part of a query response.
 type DNSRecord struct {
 
@@ -18,4 +20,25 @@ type DNSRecord struct {
 
 	// TTL is the lifetime of the record, such as 300.
 	TTL uint32
+
+	// Section is the section of the response that the record was returned
+	// in, such as answer or authority.
+	Section string
+
+	// Fields is the collection of data fields for the record keyed by field
+	// name, such as preference and mx for an MX record. Intended for use
+	// with machine-readable output formats.
+	Fields map[string]any
+}
+
+// newDNSRecord creates a DNSRecord from the given Resource Record returned in
+// the specified section of a query response.
+func newDNSRecord(record dns.RR, section string) DNSRecord {
+	return DNSRecord{
+		Value:   RecordValue(record),
+		Type:    RecordType(record.Header().Rrtype),
+		TTL:     record.Header().Ttl,
+		Section: section,
+		Fields:  RecordFields(record),
+	}
 }
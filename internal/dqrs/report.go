@@ -0,0 +1,197 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// reportTitle is the title used for shareable (Markdown, HTML) reports.
+const reportTitle string = "DNS query report"
+
+// report is the content of a shareable (Markdown, HTML) report of all
+// collected DNS query results.
+type report struct {
+
+	// Generated is the date/time that the report was generated. This is
+	// empty if the timestamp was omitted.
+	Generated string
+
+	// Queries is the list of unique query strings.
+	Queries []string
+
+	// Servers is the list of unique DNS servers.
+	Servers []string
+
+	// Types is the list of unique requested record types.
+	Types []string
+
+	// Columns is the list of column headers for the results table.
+	Columns []string
+
+	// Rows is the list of rows for the results table.
+	Rows []reportRow
+
+	// Notes is a list of notes about the results, such as the number of
+	// queries which were retried.
+	Notes []string
+}
+
+// reportRow is a single row in the results table of a shareable report.
+type reportRow struct {
+
+	// Cells is the list of values for each column.
+	Cells []string
+
+	// Outlier indicates whether the DNS server provided an answer which
+	// differs from the answer provided by the majority of DNS servers.
+	Outlier bool
+
+	// Changed indicates whether the answer, error status or record TTLs
+	// changed since the previous run when repeatedly running the same
+	// queries.
+	Changed bool
+}
+
+// Class returns the HTML class names used to highlight the row, such as
+// "outlier" for DNS servers which provided an answer which differs from the
+// majority answer and "changed" for results which changed since the
+// previous run.
+func (row reportRow) Class() string {
+
+	var classes []string
+	if row.Outlier {
+		classes = append(classes, "outlier")
+	}
+	if row.Changed {
+		classes = append(classes, "changed")
+	}
+
+	return strings.Join(classes, " ")
+}
+
+// newReport builds the content of a shareable report from all collected DNS
+// query results. Each query response is listed on a single row with all
+// records listed together.
+func (dqrs DNSQueryResponses) newReport(opts SummaryOptions) report {
+
+	var r report
+
+	if !opts.OmitTimestamp {
+		r.Generated = time.Now().Format(time.RFC3339)
+	}
+
+	seen := make(map[string]bool)
+	addUnique := func(list *[]string, kind string, value string) {
+		if value == "" || seen[kind+value] {
+			return
+		}
+		seen[kind+value] = true
+		*list = append(*list, value)
+	}
+
+	r.Columns = []string{"Server", "RTT", "Transport", "Query", "Type", "RCODE"}
+	if opts.ShowFlags {
+		r.Columns = append(r.Columns, "Flags", "Source")
+	}
+	r.Columns = append(r.Columns, "Answers", "TTL", "Consensus")
+
+	consensus := dqrs.Consensus()
+	changed := dqrs.Changed(opts.Previous)
+
+	for _, item := range dqrs {
+
+		requestType, err := RRTypeToString(item.RequestedRecordType)
+		if err != nil {
+			requestType = "rrString LookupError"
+		}
+
+		addUnique(&r.Queries, "query", item.Query)
+		addUnique(&r.Servers, "server", item.ServerName())
+		addUnique(&r.Types, "type", requestType)
+
+		serverName := item.ServerName()
+		if changed[item.ResponseKey()] {
+			serverName += changeMarker
+		}
+
+		cells := []string{
+			serverName,
+			item.rtt(),
+			item.Transport,
+			item.Query,
+			requestType,
+			item.RcodeString(),
+		}
+
+		if opts.ShowFlags {
+			cells = append(cells, item.flags(), item.source())
+		}
+
+		// Sort records before listing them
+		item.SortRecordsAsc()
+
+		var answers []string
+		var ttls []string
+		if item.QueryError != nil {
+			answers = append(answers, item.QueryError.Error())
+		}
+		for _, record := range item.SectionRecords(opts.sections()...) {
+			answer := fmt.Sprintf("%s (%s)", record.Value, record.Type)
+			if record.Section != SectionAnswer {
+				answer = fmt.Sprintf("%s (%s, %s)", record.Value, record.Type, record.Section)
+			}
+			answers = append(answers, answer)
+			ttls = append(ttls, fmt.Sprint(record.TTL))
+		}
+
+		result := consensus.Result(item)
+
+		cells = append(
+			cells,
+			strings.Join(answers, ", "),
+			strings.Join(ttls, ", "),
+			result.String(),
+		)
+
+		r.Rows = append(r.Rows, reportRow{
+			Cells:   cells,
+			Outlier: result.Outlier,
+			Changed: changed[item.ResponseKey()],
+		})
+	}
+
+	if pacedCount, longestDelay := dqrs.Paced(); pacedCount > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf(
+			"%s %d queries delayed by rate limiting (longest delay: %v)",
+			pacingMarker,
+			pacedCount,
+			longestDelay.Round(time.Millisecond),
+		))
+	}
+
+	if retriedCount := dqrs.Retried(); retriedCount > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf(
+			"%s %d queries succeeded after retry",
+			retryMarker,
+			retriedCount,
+		))
+	}
+
+	if changedCount := countTrue(changed); changedCount > 0 {
+		r.Notes = append(r.Notes, fmt.Sprintf(
+			"%s %d results changed since previous run",
+			changeMarker,
+			changedCount,
+		))
+	}
+
+	return r
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ResolveHost resolves the given hostname to all of its IPv4 and IPv6
+// addresses. If specified, the bootstrap DNS server is used to resolve the
+// hostname instead of the system resolver. An error is returned if the
+// hostname could not be resolved.
+func ResolveHost(host string, bootstrapServer string, timeout time.Duration) ([]string, error) {
+
+	resolver := net.DefaultResolver
+
+	if bootstrapServer != "" {
+		_, bootstrapAddress := splitServerAddress(bootstrapServer, defaultDNSPort)
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, bootstrapAddress)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve hostname %q: %w", host, err)
+	}
+
+	ipAddresses := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		ipAddresses = append(ipAddresses, addr.Unmap().String())
+	}
+
+	return ipAddresses, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// startTestBootstrapServer starts a local UDP DNS server which resolves
+// testServerName to the given IPv4 and IPv6 addresses and returns NXDOMAIN
+// for all other names. The address of the server is returned.
+func startTestBootstrapServer(t *testing.T, ipv4 string, ipv6 string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start bootstrap listener: %v", err)
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+
+			q := r.Question[0]
+			hdr := dns.RR_Header{Name: q.Name, Rrtype: q.Qtype, Class: dns.ClassINET, Ttl: 300}
+			switch {
+			case q.Name != dns.Fqdn(testServerName):
+				m.Rcode = dns.RcodeNameError
+			case q.Qtype == dns.TypeA:
+				m.Answer = append(m.Answer, &dns.A{Hdr: hdr, A: net.ParseIP(ipv4)})
+			case q.Qtype == dns.TypeAAAA:
+				m.Answer = append(m.Answer, &dns.AAAA{Hdr: hdr, AAAA: net.ParseIP(ipv6)})
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	return conn.LocalAddr().String()
+}
+
+func TestResolveHost(t *testing.T) {
+
+	bootstrap := startTestBootstrapServer(t, "192.0.2.10", "2001:db8::10")
+
+	got, err := ResolveHost(testServerName, bootstrap, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to resolve %q: %v", testServerName, err)
+	}
+
+	slices.Sort(got)
+	want := []string{"192.0.2.10", "2001:db8::10"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestResolveHostNotFound(t *testing.T) {
+
+	bootstrap := startTestBootstrapServer(t, "192.0.2.10", "2001:db8::10")
+
+	if got, err := ResolveHost("missing.test", bootstrap, 5*time.Second); err == nil {
+		t.Errorf("expected error, got %v", got)
+	}
+}
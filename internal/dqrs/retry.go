@@ -0,0 +1,77 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/miekg/dns"
+)
+
+// maxRetryBackoff is the maximum backoff delay applied to any one retry
+// attempt, excluding jitter. The backoff delay stops doubling once this is
+// reached.
+const maxRetryBackoff = 30 * time.Second
+
+// retryDelay returns the amount of time to wait before submitting the given
+// retry attempt. The backoff delay is doubled for each retry attempt after
+// the first, up to maxRetryBackoff, and a random jitter of up to the
+// configured maximum is added. An initial backoff delay larger than
+// maxRetryBackoff is used as-is.
+func (opts QueryOptions) retryDelay(retry int) time.Duration {
+
+	delay := opts.RetryBackoff
+	for i := 1; i < retry && delay < maxRetryBackoff; i++ {
+		delay = min(delay*2, maxRetryBackoff)
+	}
+
+	if opts.RetryJitter > 0 {
+		// #nosec G404
+		// Jitter only spreads out retries; cryptographic randomness is not
+		// required.
+		delay += rand.N(opts.RetryJitter)
+	}
+
+	return delay
+}
+
+// exchangeWithRetry submits the given message to the specified server,
+// resubmitting the message up to the configured number of retries if the
+// exchange fails. The details of the last exchange and the total number of
+// attempts made are returned along with any error from the last attempt.
+func exchangeWithRetry(msg *dns.Msg, server string, opts QueryOptions) (exchangeResult, int, error) {
+
+	attempts := 1
+	result, err := exchange(msg, server, opts)
+
+	for retry := 1; err != nil && retry <= opts.Retries; retry++ {
+		delay := opts.retryDelay(retry)
+
+		log.WithFields(log.Fields{
+			"server":  server,
+			"query":   msg.Question[0].Name,
+			"attempt": attempts,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		}).Debug("Query attempt failed, retrying")
+
+		time.Sleep(delay)
+
+		attempts++
+		result, err = exchange(msg, server, opts)
+	}
+
+	if err != nil && attempts > 1 {
+		err = fmt.Errorf("query failed after %d attempts: %w", attempts, err)
+	}
+
+	return result, attempts, err
+}
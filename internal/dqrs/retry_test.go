@@ -0,0 +1,61 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		backoff time.Duration
+		retry   int
+		want    time.Duration
+	}{
+		{name: "first retry", backoff: 250 * time.Millisecond, retry: 1, want: 250 * time.Millisecond},
+		{name: "doubled", backoff: 250 * time.Millisecond, retry: 3, want: time.Second},
+		{name: "capped", backoff: 250 * time.Millisecond, retry: 10, want: maxRetryBackoff},
+		{name: "no overflow", backoff: time.Hour, retry: 64, want: time.Hour},
+		{name: "no backoff", backoff: 0, retry: 5, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := QueryOptions{RetryBackoff: tt.backoff}
+			if got := opts.retryDelay(tt.retry); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetriedCount(t *testing.T) {
+
+	responses := DNSQueryResponses{
+		{Attempts: 1, Responded: true},
+		{Attempts: 2, Responded: true},
+		{Attempts: 2, Responded: true, QueryError: ErrNoRecordsFound},
+		{Attempts: 3, QueryError: errors.New("i/o timeout")},
+	}
+
+	if got := responses.Retried(); got != 2 {
+		t.Errorf("got %d queries succeeded after retry, want 2", got)
+	}
+
+	for i, item := range responses {
+		retried := strings.HasSuffix(item.rtt(), retryMarker)
+		if want := i == 1 || i == 2; retried != want {
+			t.Errorf("response %d: got retry marker %t, want %t", i, retried, want)
+		}
+	}
+}
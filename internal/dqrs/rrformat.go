@@ -0,0 +1,157 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/miekg/dns"
+)
+
+// RecordValue returns a concise string representation of the data (RDATA)
+// for the given Resource Record, such as "10 mail.example.com." for an MX
+// record. This is the presentation format used by the dns package (and most
+// DNS tools) without the leading owner name, TTL, class and type fields.
+func RecordValue(rr dns.RR) string {
+
+	// The presentation format separates the owner name, TTL, class, type and
+	// data fields using tabs.
+	fields := strings.SplitN(rr.String(), "\t", 5)
+	if len(fields) < 5 {
+		return strings.TrimSpace(rr.String())
+	}
+
+	return strings.TrimSpace(fields[4])
+}
+
+// RecordType returns the name of the given Resource Record type, such as MX.
+// Types not known to the dns package are named using the generic TYPE###
+// notation from RFC 3597.
+func RecordType(rrType uint16) string {
+
+	name, ok := dns.TypeToString[rrType]
+	if !ok {
+		return fmt.Sprintf("TYPE%d", rrType)
+	}
+
+	return name
+}
+
+// RecordFields returns the data (RDATA) fields for the given Resource Record
+// keyed by field name (e.g., "preference" and "mx" for an MX record). Field
+// names are derived from the dns package types for each Resource Record
+// type. Numeric fields are returned as numbers and list fields are returned
+// as lists of strings; all other fields are returned as strings. This is
+// intended for use with machine-readable output formats.
+func RecordFields(rr dns.RR) map[string]any {
+
+	fields := make(map[string]any)
+
+	v := reflect.ValueOf(rr)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+
+	addRecordFields(fields, v)
+
+	return fields
+}
+
+// addRecordFields adds the fields of the given dns package struct to the
+// collection of fields. The fields of embedded structs (e.g., the SVCB
+// fields embedded in an HTTPS record) are added as if they were fields of
+// the outer struct.
+func addRecordFields(fields map[string]any, v reflect.Value) {
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		switch {
+		// Skip the common header and any unexported fields
+		case field.Name == "Hdr" || !field.IsExported():
+			continue
+
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			addRecordFields(fields, v.Field(i))
+
+		default:
+			fields[fieldName(field.Name)] = fieldValue(v.Field(i))
+		}
+	}
+}
+
+// fieldName converts the given dns package struct field name to a lowercase
+// name with words separated by underscores (e.g., KeyTag becomes key_tag).
+func fieldName(name string) string {
+
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// fieldValue converts the given dns package struct field value to a value
+// suitable for machine-readable output.
+func fieldValue(v reflect.Value) any {
+
+	if ip, ok := v.Interface().(net.IP); ok {
+		return ip.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+
+	case reflect.Bool:
+		return v.Bool()
+
+	case reflect.String:
+		return v.String()
+
+	case reflect.Slice:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			switch element := v.Index(i).Interface().(type) {
+
+			// Include the key for SVCB and HTTPS record parameters (e.g.,
+			// alpn=h2,h3) as the value alone is ambiguous.
+			case dns.SVCBKeyValue:
+				values = append(values, element.Key().String()+"="+element.String())
+
+			default:
+				values = append(values, fmt.Sprint(element))
+			}
+		}
+		return values
+
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// testDSDigest is the digest used for the DS record test cases.
+const testDSDigest = "E2D3C916F6DEEAC73294E8268FB5885044A833FC5459588F4A9184CFC41A5766"
+
+func TestRecordFormat(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		rr         string
+		wantValue  string
+		wantFields map[string]any
+	}{
+		{
+			name:      "A",
+			rr:        "example.com. 300 IN A 192.0.2.1",
+			wantValue: "192.0.2.1",
+			wantFields: map[string]any{
+				"a": "192.0.2.1",
+			},
+		},
+		{
+			name:      "AAAA",
+			rr:        "example.com. 300 IN AAAA 2001:db8::1",
+			wantValue: "2001:db8::1",
+			wantFields: map[string]any{
+				"aaaa": "2001:db8::1",
+			},
+		},
+		{
+			name:      "MX",
+			rr:        "example.com. 300 IN MX 10 mail.example.com.",
+			wantValue: "10 mail.example.com.",
+			wantFields: map[string]any{
+				"preference": uint64(10),
+				"mx":         "mail.example.com.",
+			},
+		},
+		{
+			name:      "TXT with multiple strings",
+			rr:        `example.com. 300 IN TXT "v=spf1 -all" "second string"`,
+			wantValue: `"v=spf1 -all" "second string"`,
+			wantFields: map[string]any{
+				"txt": []string{"v=spf1 -all", "second string"},
+			},
+		},
+		{
+			name:      "SOA",
+			rr:        "example.com. 300 IN SOA ns1.example.com. hostmaster.example.com. 2021010101 7200 3600 1209600 300",
+			wantValue: "ns1.example.com. hostmaster.example.com. 2021010101 7200 3600 1209600 300",
+			wantFields: map[string]any{
+				"ns":      "ns1.example.com.",
+				"mbox":    "hostmaster.example.com.",
+				"serial":  uint64(2021010101),
+				"refresh": uint64(7200),
+				"retry":   uint64(3600),
+				"expire":  uint64(1209600),
+				"minttl":  uint64(300),
+			},
+		},
+		{
+			name:      "CAA",
+			rr:        `example.com. 300 IN CAA 0 issue "letsencrypt.org"`,
+			wantValue: `0 issue "letsencrypt.org"`,
+			wantFields: map[string]any{
+				"flag":  uint64(0),
+				"tag":   "issue",
+				"value": "letsencrypt.org",
+			},
+		},
+		{
+			name:      "DS",
+			rr:        "example.com. 300 IN DS 12345 13 2 " + testDSDigest,
+			wantValue: "12345 13 2 " + testDSDigest,
+			wantFields: map[string]any{
+				"key_tag":     uint64(12345),
+				"algorithm":   uint64(13),
+				"digest_type": uint64(2),
+				"digest":      testDSDigest,
+			},
+		},
+		{
+			name:      "SVCB",
+			rr:        `example.com. 300 IN SVCB 1 svc.example.com. alpn="h2"`,
+			wantValue: `1 svc.example.com. alpn="h2"`,
+			wantFields: map[string]any{
+				"priority": uint64(1),
+				"target":   "svc.example.com.",
+				"value":    []string{"alpn=h2"},
+			},
+		},
+		{
+			// The SVCB fields embedded in an HTTPS record are returned as
+			// if they were fields of the HTTPS record.
+			name:      "HTTPS",
+			rr:        `example.com. 300 IN HTTPS 1 . alpn="h2,h3" port=443`,
+			wantValue: `1 . alpn="h2,h3" port="443"`,
+			wantFields: map[string]any{
+				"priority": uint64(1),
+				"target":   ".",
+				"value":    []string{"alpn=h2,h3", "port=443"},
+			},
+		},
+		{
+			name:      "NAPTR",
+			rr:        `example.com. 300 IN NAPTR 100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`,
+			wantValue: `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`,
+			wantFields: map[string]any{
+				"order":       uint64(100),
+				"preference":  uint64(10),
+				"flags":       "S",
+				"service":     "SIP+D2U",
+				"regexp":      "",
+				"replacement": "_sip._udp.example.com.",
+			},
+		},
+		{
+			name:      "RFC 3597 unknown type",
+			rr:        `example.com. 300 IN TYPE65534 \# 3 abcdef`,
+			wantValue: `\# 3 abcdef`,
+			wantFields: map[string]any{
+				"rdata": "abcdef",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr, err := dns.NewRR(tt.rr)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", tt.rr, err)
+			}
+
+			if got := RecordValue(rr); got != tt.wantValue {
+				t.Errorf("got value %q, want %q", got, tt.wantValue)
+			}
+
+			if got := RecordFields(rr); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("got fields %#v, want %#v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRecordType(t *testing.T) {
+
+	tests := []struct {
+		rrType uint16
+		want   string
+	}{
+		{rrType: dns.TypeA, want: "A"},
+		{rrType: dns.TypeHTTPS, want: "HTTPS"},
+		{rrType: 65534, want: "TYPE65534"},
+	}
+
+	for _, tt := range tests {
+		if got := RecordType(tt.rrType); got != tt.want {
+			t.Errorf("RecordType(%d) = %q, want %q", tt.rrType, got, tt.want)
+		}
+	}
+}
+
+func TestFieldName(t *testing.T) {
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "A", want: "a"},
+		{name: "AAAA", want: "aaaa"},
+		{name: "Txt", want: "txt"},
+		{name: "Minttl", want: "minttl"},
+		{name: "KeyTag", want: "key_tag"},
+		{name: "NextDomain", want: "next_domain"},
+		{name: "PublicKeyAlgorithm", want: "public_key_algorithm"},
+		{name: "PSDNAddress", want: "psdn_address"},
+		{name: "MACSize", want: "mac_size"},
+		{name: "Locator64", want: "locator64"},
+	}
+
+	for _, tt := range tests {
+		if got := fieldName(tt.name); got != tt.want {
+			t.Errorf("fieldName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -9,62 +9,139 @@ package dqrs
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 	"time"
 
 	"github.com/apex/log"
 )
 
-// PrintSummary generates a summary of all collected DNS query results in the
-// specified format. If specified, the date/time that the results are
-// generated is omitted from the results output.
-func (dqrs DNSQueryResponses) PrintSummary(outputFormat string, omitTimestamp bool) {
+// Markers appended to the round-trip time of queries in the results summary.
+const (
+	// pacingMarker flags queries which were delayed in order to honor
+	// configured rate limits.
+	pacingMarker = "*"
 
-	w := tabwriter.NewWriter(os.Stdout, 4, 4, 4, ' ', 0)
+	// retryMarker flags queries which succeeded only after retry.
+	retryMarker = "+"
+)
 
-	// Add some lead-in spacing to better separate any earlier log messages from
-	// summary output
-	_, _ = fmt.Fprintf(w, "\n\n")
+// SummaryOptions is a collection of settings which control the content and
+// format of the results summary.
+type SummaryOptions struct {
 
-	// REMINDER: Column cells must be tab-terminated, not tab-separated:
-	// non-tab terminated trailing text at the end of a line forms a cell but
-	// that cell is not part of an aligned column.
-	var headerRowTmpl string
-	var separatorRowTmpl string
-	var recordRowErrorTmpl string
-	var recordRowSuccessTmpl string
+	// OutputFormat specifies whether the results summary is composed of a
+	// single comma-separated line of records for a query, or whether the
+	// records are returned one per line.
+	OutputFormat string
 
-	switch {
+	// OmitTimestamp specifies whether the date/time that the results are
+	// generated is omitted from the results summary.
+	OmitTimestamp bool
 
-	case !dqrs.RecordsFound():
+	// ShowFlags specifies whether the header flags set in each response and
+	// whether the answer came from an authoritative DNS server are included
+	// in the results summary.
+	ShowFlags bool
 
-		headerRowTmpl = "Server\tRTT\tQuery\tType\tAnswer\t"
-		separatorRowTmpl = "---\t---\t---\t---\t---\t"
-		recordRowErrorTmpl = "%s\t%s\t%s\t%s\t%s\t\n"
-		recordRowSuccessTmpl = "%s\t%s\t%s\t%s\t%s\t\n"
+	// ShowSections specifies whether records from the authority and
+	// additional sections of each response are included in the results
+	// summary along with the section each record was returned in.
+	ShowSections bool
 
-	case outputFormat == ResultsOutputMultiLine:
+	// Previous is the collection of results from the previous run when
+	// repeatedly running the same queries. If specified, results which
+	// changed since the previous run are flagged.
+	Previous DNSQueryResponses
 
-		headerRowTmpl = "Server\tRTT\tQuery\tType\tAnswer\tAnswer Type\tTTL\t"
-		separatorRowTmpl = "---\t---\t---\t---\t---\t---\t---\t"
-		recordRowErrorTmpl = "%s\t%s\t%s\t%s\t%s\t\t\t\n"
-		recordRowSuccessTmpl = "%s\t%s\t%s\t%s\t%s\t%s\t%d\t\n"
+	// Template is the user-provided output template used to generate the
+	// results summary. If specified, this takes precedence over the output
+	// format.
+	Template *template.Template
+}
 
-	case outputFormat == ResultsOutputSingleLine:
-		headerRowTmpl = "Server\tRTT\tQuery\tType\tAnswers\tTTL\t"
-		separatorRowTmpl = "---\t---\t---\t---\t---\t---\t"
-		recordRowErrorTmpl = "%s\t%s\t%s\t%s\t%s\t\t\n"
-		recordRowSuccessTmpl = "%s\t%s\t%s\t%s\t%s\t%s\t\n"
+// sections returns the sections of each response which records are
+// displayed for.
+func (opts SummaryOptions) sections() []string {
 
+	if opts.ShowSections {
+		return []string{SectionAnswer, SectionAuthority, SectionAdditional}
 	}
 
-	// Header row in output
-	_, _ = fmt.Fprintln(w, headerRowTmpl)
+	return []string{SectionAnswer}
+}
+
+// Tabular indicates whether the results summary is generated as a table
+// intended to be read directly instead of being processed by other tools.
+func (opts SummaryOptions) Tabular() bool {
+
+	if opts.Template != nil {
+		return false
+	}
+
+	switch opts.OutputFormat {
+	case ResultsOutputSingleLine, ResultsOutputMultiLine:
+		return true
+	default:
+		return false
+	}
+}
+
+// PrintSummary generates a summary of all collected DNS query results using
+// the specified settings.
+func (dqrs DNSQueryResponses) PrintSummary(opts SummaryOptions) {
 
-	// Separator row
-	_, _ = fmt.Fprintln(w, separatorRowTmpl)
+	var err error
+	switch {
+	case opts.Template != nil:
+		err = dqrs.writeTemplate(os.Stdout, opts)
+	case opts.OutputFormat == ResultsOutputJSON:
+		err = dqrs.writeJSON(os.Stdout, opts)
+	case opts.OutputFormat == ResultsOutputCSV:
+		err = dqrs.writeDelimited(os.Stdout, ',', opts)
+	case opts.OutputFormat == ResultsOutputTSV:
+		err = dqrs.writeDelimited(os.Stdout, '\t', opts)
+	case opts.OutputFormat == ResultsOutputMarkdown:
+		err = dqrs.writeMarkdown(os.Stdout, opts)
+	case opts.OutputFormat == ResultsOutputHTML:
+		err = dqrs.writeHTML(os.Stdout, opts)
+	default:
+		err = dqrs.writeTable(os.Stdout, opts)
+	}
+
+	if err != nil {
+		log.Errorf("Error generating results summary: %v", err)
+	}
+}
+
+// writeTable writes a summary of all collected DNS query results to the given
+// writer as a table with aligned columns.
+func (dqrs DNSQueryResponses) writeTable(output io.Writer, opts SummaryOptions) error {
+
+	w := newTableWriter(output)
+
+	// Add some lead-in spacing to better separate any earlier log messages from
+	// summary output
+	_, _ = fmt.Fprintf(w, "\n\n")
+
+	recordsFound := dqrs.RecordsFound()
+	if opts.ShowSections {
+		recordsFound = recordsFound || dqrs.sectionRecordsFound()
+	}
+	columns := opts.columns(recordsFound)
+
+	consensus := dqrs.Consensus()
+
+	var changed map[ResponseKey]bool
+	if opts.Previous != nil {
+		changed = dqrs.Changed(opts.Previous)
+	}
+
+	// Header and separator rows in output
+	writeTableHeader(w, columns)
 
 	for _, item := range dqrs {
 		// Building with `go build -gcflags=all=-d=loopvar=2` identified this
@@ -84,71 +161,146 @@ func (dqrs DNSQueryResponses) PrintSummary(outputFormat string, omitTimestamp bo
 			requestType = "rrString LookupError"
 		}
 
-		// if any errors were recorded when querying DNS server show those
-		// instead of attempting to show real results
-		if item.QueryError != nil {
-			_, _ = fmt.Fprintf(w,
-				recordRowErrorTmpl,
-				item.Server,
-				item.ResponseTime.Round(time.Millisecond),
-				item.Query,
-				requestType,
-				item.QueryError.Error(),
-			)
-			continue
+		serverName := item.ServerName()
+		if consensus.Result(item).Outlier {
+			serverName += divergenceMarker
+		}
+
+		if changed[item.ResponseKey()] {
+			serverName += changeMarker
+		}
+
+		leadingCells := []string{
+			serverName,
+			item.rtt(),
+			item.Transport,
+			item.Query,
+			requestType,
+			item.RcodeString(),
+		}
+
+		if opts.ShowFlags {
+			leadingCells = append(leadingCells, item.flags(), item.source())
 		}
 
 		// Sort records before printing them
 		item.SortRecordsAsc()
 
-		switch outputFormat {
+		records := item.SectionRecords(opts.sections()...)
+
+		// if any errors were recorded when querying DNS server show those
+		// instead of attempting to show real results. If requested, records
+		// from other sections of the response (e.g., the SOA record provided
+		// with a negative answer) are shown after the error.
+		if item.QueryError != nil {
+			errorCells := leadingCells
+			if opts.ShowSections {
+				errorCells = append(errorCells, "")
+			}
+			writeSummaryRow(w, columns, append(errorCells, item.QueryError.Error()))
+
+			if !opts.ShowSections || len(records) == 0 {
+				continue
+			}
+		}
+
+		switch opts.OutputFormat {
 		case ResultsOutputMultiLine:
 
-			for _, record := range item.Records() {
-				_, _ = fmt.Fprintf(w,
-					recordRowSuccessTmpl,
-					item.Server,
-					item.ResponseTime.Round(time.Millisecond),
-					item.Query,
-					requestType,
+			for _, record := range records {
+				recordCells := leadingCells[:len(leadingCells):len(leadingCells)]
+				if opts.ShowSections {
+					recordCells = append(recordCells, record.Section)
+				}
+
+				writeSummaryRow(w, columns, append(
+					recordCells,
 					record.Value,
 
 					// Display request type from record, which may not match the
 					// original request type (e.g., CNAME and A records returned
 					// for original lookup.
 					record.Type,
-					record.TTL,
-				)
+					fmt.Sprint(record.TTL),
+				))
 			}
 
 		case ResultsOutputSingleLine:
 
-			var responses []string
-			var ttls []string
-			for _, record := range item.Records() {
-				response := fmt.Sprintf(
-					"%s (%s)",
-					record.Value,
-					record.Type,
-				)
-				responses = append(responses, response)
-				ttls = append(ttls, fmt.Sprint(record.TTL))
+			// Records from each section are listed on a separate line if
+			// showing records from all sections.
+			for _, section := range opts.sections() {
+				var responses []string
+				var ttls []string
+				for _, record := range records {
+					if record.Section != section {
+						continue
+					}
+
+					response := fmt.Sprintf(
+						"%s (%s)",
+						record.Value,
+						record.Type,
+					)
+					responses = append(responses, response)
+					ttls = append(ttls, fmt.Sprint(record.TTL))
+				}
+
+				if len(responses) == 0 {
+					continue
+				}
+
+				recordCells := leadingCells[:len(leadingCells):len(leadingCells)]
+				if opts.ShowSections {
+					recordCells = append(recordCells, section)
+				}
+
+				writeSummaryRow(w, columns, append(
+					recordCells,
+					strings.Join(responses, ", "),
+					strings.Join(ttls, ", "),
+				))
 			}
+		}
+
+	}
+
+	if pacedCount, longestDelay := dqrs.Paced(); pacedCount > 0 {
+		_, _ = fmt.Fprintf(
+			w,
+			"\n%s %d queries delayed by rate limiting (longest delay: %v)\n",
+			pacingMarker,
+			pacedCount,
+			longestDelay.Round(time.Millisecond),
+		)
+	}
 
-			_, _ = fmt.Fprintf(w,
-				recordRowSuccessTmpl,
-				item.Server,
-				item.ResponseTime.Round(time.Millisecond),
-				item.Query,
-				requestType,
-				strings.Join(responses, ", "),
-				strings.Join(ttls, ", "),
-			)
+	if retriedCount := dqrs.Retried(); retriedCount > 0 {
+		_, _ = fmt.Fprintf(
+			w,
+			"\n%s %d queries succeeded after retry\n",
+			retryMarker,
+			retriedCount,
+		)
+	}
+
+	if divergenceNotes := dqrs.divergenceNotes(consensus); len(divergenceNotes) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, note := range divergenceNotes {
+			_, _ = fmt.Fprintf(w, "%s %s\n", divergenceMarker, note)
 		}
+	}
 
+	if changedCount := countTrue(changed); changedCount > 0 {
+		_, _ = fmt.Fprintf(
+			w,
+			"\n%s %d results changed since previous run\n",
+			changeMarker,
+			changedCount,
+		)
 	}
 
-	if !omitTimestamp {
+	if !opts.OmitTimestamp {
 		_, _ = fmt.Fprintf(
 			w,
 			"\nQuery Performed: %v",
@@ -159,7 +311,141 @@ func (dqrs DNSQueryResponses) PrintSummary(outputFormat string, omitTimestamp bo
 	_, _ = fmt.Fprintln(w)
 
 	if err := w.Flush(); err != nil {
-		log.Errorf("Error flushing tabwriter: %v", err.Error())
+		return fmt.Errorf("error flushing tabwriter: %w", err)
+	}
+
+	return nil
+}
+
+// columns returns the column headers for the results summary. Columns for
+// record details are only included if records were found for at least one
+// query.
+func (opts SummaryOptions) columns(recordsFound bool) []string {
+
+	columns := []string{"Server", "RTT", "Transport", "Query", "Type", "RCODE"}
+
+	if opts.ShowFlags {
+		columns = append(columns, "Flags", "Source")
+	}
+
+	if opts.ShowSections {
+		columns = append(columns, "Section")
+	}
+
+	switch {
+	case !recordsFound:
+		columns = append(columns, "Answer")
+	case opts.OutputFormat == ResultsOutputSingleLine:
+		columns = append(columns, "Answers", "TTL")
+	default:
+		columns = append(columns, "Answer", "Answer Type", "TTL")
+	}
+
+	return columns
+}
+
+// auxOutput returns the destination for supplementary output which follows
+// the results summary, such as the expected values or the consistency
+// timeline. This is stdout for table output formats or stderr otherwise so
+// that machine-readable results output is not affected.
+func (opts SummaryOptions) auxOutput() io.Writer {
+
+	if !opts.Tabular() {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// newTableWriter creates a tabwriter used to write a table with aligned
+// columns to the given writer.
+func newTableWriter(output io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(output, 4, 4, 4, ' ', 0)
+}
+
+// writeTableHeader writes a header row for the given columns followed by a
+// separator row.
+func writeTableHeader(w io.Writer, columns []string) {
+
+	separators := make([]string, len(columns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	writeSummaryRow(w, columns, columns)
+	writeSummaryRow(w, columns, separators)
+}
+
+// writeSummaryRow writes the given cells as a single row of the results
+// summary, adding empty cells as needed to fill out the row.
+func writeSummaryRow(w io.Writer, columns []string, cells []string) {
+
+	// REMINDER: Column cells must be tab-terminated, not tab-separated:
+	// non-tab terminated trailing text at the end of a line forms a cell but
+	// that cell is not part of an aligned column.
+	var row strings.Builder
+	for i := range columns {
+		if i < len(cells) {
+			row.WriteString(cells[i])
+		}
+		row.WriteString("\t")
+	}
+
+	_, _ = fmt.Fprintln(w, row.String())
+}
+
+// rtt returns the round-trip time for the query formatted for display. The
+// round-trip time is flagged if submission of the query was delayed in order
+// to honor configured rate limits or if the query succeeded only after the
+// query was retried.
+func (dqr DNSQueryResponse) rtt() string {
+
+	rtt := dqr.ResponseTime.Round(time.Millisecond).String()
+	if dqr.PacingDelay > 0 {
+		rtt += pacingMarker
+	}
+
+	if dqr.succeededAfterRetry() {
+		rtt += retryMarker
+	}
+
+	return rtt
+}
+
+// flags returns the header flags set in the response formatted for display
+// or an empty string if the DNS server did not respond.
+func (dqr DNSQueryResponse) flags() string {
+
+	if !dqr.Responded {
+		return ""
+	}
+
+	return dqr.Flags.String()
+}
+
+// source returns whether the answer came from an authoritative DNS server
+// formatted for display or an empty string if the DNS server did not respond.
+func (dqr DNSQueryResponse) source() string {
+
+	switch {
+	case !dqr.Responded:
+		return ""
+	case dqr.Flags.Authoritative:
+		return "authoritative"
+	default:
+		return "non-authoritative"
+	}
+}
+
+// sectionRecordsFound indicates whether any query responses include records
+// in the authority or additional sections.
+func (dqrs DNSQueryResponses) sectionRecordsFound() bool {
+
+	for i := range dqrs {
+		if len(dqrs[i].SectionRecords(SectionAuthority, SectionAdditional)) > 0 {
+			return true
+		}
 	}
 
+	return false
 }
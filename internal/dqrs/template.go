@@ -0,0 +1,242 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFilePrefix indicates that an output template is read from the file
+// specified after the prefix instead of being provided inline.
+const templateFilePrefix string = "@"
+
+// TemplateData is the view model which user-provided output templates are
+// executed against.
+type TemplateData struct {
+
+	// Timestamp is the RFC 3339 date/time that the results were generated.
+	// This is empty if the user opted to omit the timestamp.
+	Timestamp string
+
+	// Results is the collection of query results, one for each query
+	// submitted to each DNS server.
+	Results []TemplateResult
+}
+
+// TemplateResult is the view model for a single DNS query and response.
+type TemplateResult struct {
+
+	// Server is the address of the DNS server.
+	Server string
+
+	// ServerLabel is the label provided for the DNS server, if any.
+	ServerLabel string
+
+	// ServerHostname is the hostname originally specified for the DNS server,
+	// if any.
+	ServerHostname string
+
+	// ServerName is the name used to identify the DNS server in the results
+	// summary; the label (or hostname) if provided along with the server
+	// address, the server address otherwise.
+	ServerName string
+
+	// Query is the query string.
+	Query string
+
+	// Type is the requested record type, such as A or MX.
+	Type string
+
+	// Transport is the network transport which produced the response.
+	Transport string
+
+	// RTT is the round-trip time for the query.
+	RTT time.Duration
+
+	// Attempts is the number of times the query was submitted.
+	Attempts int
+
+	// Retried indicates whether the query was retried.
+	Retried bool
+
+	// Responded indicates whether the DNS server responded to the query.
+	Responded bool
+
+	// RCODE is the response code, such as NOERROR or NXDOMAIN. This is empty
+	// if the DNS server did not respond.
+	RCODE string
+
+	// Flags is the list of header flags set in the response, such as
+	// "aa rd ra". This is empty if the DNS server did not respond.
+	Flags string
+
+	// Error is the error which occurred for the query, if any.
+	Error string
+
+	// Outlier indicates whether the DNS server provided an answer which
+	// differs from the answer provided by the majority of DNS servers for
+	// the same query and record type.
+	Outlier bool
+
+	// Consensus is a short description of how the answer compares to the
+	// majority answer, such as "agrees with 3/4 servers" or "diverges from
+	// 3/4 servers".
+	Consensus string
+
+	// Changed indicates whether the answer, record TTLs or error status
+	// changed since the previous run when repeatedly running the same
+	// queries.
+	Changed bool
+
+	// Records is the collection of records returned for the query. Records
+	// from the authority and additional sections are included if requested.
+	Records []DNSRecord
+}
+
+// templateFuncs is the collection of helper functions available to
+// user-provided output templates.
+var templateFuncs = template.FuncMap{
+
+	// join returns the values of the given records joined by the specified
+	// separator.
+	"join": func(sep string, records []DNSRecord) string {
+		return strings.Join(recordValues(records), sep)
+	},
+
+	// values returns the values of the given records.
+	"values": recordValues,
+
+	// byType returns the given records which are of the specified record
+	// type.
+	"byType": func(recordType string, records []DNSRecord) []DNSRecord {
+		filtered := make([]DNSRecord, 0, len(records))
+		for _, record := range records {
+			if strings.EqualFold(record.Type, recordType) {
+				filtered = append(filtered, record)
+			}
+		}
+
+		return filtered
+	},
+
+	// rtt returns the given round-trip time rounded to the nearest
+	// millisecond, such as 12ms.
+	"rtt": func(rtt time.Duration) string {
+		return rtt.Round(time.Millisecond).String()
+	},
+}
+
+// NewOutputTemplate parses the given user-provided output template. The
+// template is read from a file if the value is prefixed with @ (e.g.,
+// @/path/to/template.tmpl). An error is returned if the template cannot be
+// read or parsed.
+func NewOutputTemplate(value string) (*template.Template, error) {
+
+	name := "output-template"
+	text := value
+
+	if strings.HasPrefix(value, templateFilePrefix) {
+		filename := filepath.Clean(strings.TrimPrefix(value, templateFilePrefix))
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read output template file %q: %w", filename, err)
+		}
+
+		name = filepath.Base(filename)
+		text = string(content)
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// writeTemplate writes all collected DNS query results to the given writer
+// using the user-provided output template.
+func (dqrs DNSQueryResponses) writeTemplate(w io.Writer, opts SummaryOptions) error {
+
+	data := TemplateData{
+		Results: make([]TemplateResult, 0, len(dqrs)),
+	}
+
+	if !opts.OmitTimestamp {
+		data.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	consensus := dqrs.Consensus()
+	changed := dqrs.Changed(opts.Previous)
+	for _, item := range dqrs {
+		result := item.templateResult(opts, consensus.Result(item))
+		result.Changed = changed[item.ResponseKey()]
+		data.Results = append(data.Results, result)
+	}
+
+	if err := opts.Template.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute output template: %w", err)
+	}
+
+	return nil
+}
+
+// templateResult converts the DNS query response to its output template view
+// model along with the given consensus outcome.
+func (dqr DNSQueryResponse) templateResult(opts SummaryOptions, consensus ConsensusResult) TemplateResult {
+
+	requestType, err := RRTypeToString(dqr.RequestedRecordType)
+	if err != nil {
+		requestType = ""
+	}
+
+	result := TemplateResult{
+		Server:         dqr.Server,
+		ServerLabel:    dqr.ServerLabel,
+		ServerHostname: dqr.ServerHostname,
+		ServerName:     dqr.ServerName(),
+		Query:          dqr.Query,
+		Type:           requestType,
+		Transport:      dqr.Transport,
+		RTT:            dqr.ResponseTime,
+		Attempts:       dqr.Attempts,
+		Retried:        dqr.Retried(),
+		Responded:      dqr.Responded,
+		RCODE:          dqr.RcodeString(),
+		Flags:          dqr.flags(),
+		Outlier:        consensus.Outlier,
+		Consensus:      consensus.String(),
+	}
+
+	if dqr.QueryError != nil {
+		result.Error = dqr.QueryError.Error()
+	}
+
+	// Sort records so that output is consistent between runs
+	dqr.SortRecordsAsc()
+	result.Records = dqr.SectionRecords(opts.sections()...)
+
+	return result
+}
+
+// recordValues returns the values of the given records.
+func recordValues(records []DNSRecord) []string {
+
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		values = append(values, record.Value)
+	}
+
+	return values
+}
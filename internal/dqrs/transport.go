@@ -0,0 +1,207 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// QueryOptions is a collection of settings applied when submitting a query to
+// a DNS server.
+type QueryOptions struct {
+
+	// Timeout is the maximum amount of time allowed for a query to complete
+	// before it times out.
+	Timeout time.Duration
+
+	// Transport is the network transport used to submit the query. If not
+	// specified, UDP is used.
+	Transport string
+
+	// TLSConfig is the base TLS configuration used when submitting queries
+	// using an encrypted transport. If not specified, the default TLS
+	// configuration is used.
+	TLSConfig *tls.Config
+
+	// TLSServerName is the name used to verify the certificate presented by
+	// the DNS server when using an encrypted transport. If not specified, the
+	// server value is used.
+	TLSServerName string
+
+	// DoHMethod is the HTTP method used to submit DNS-over-HTTPS (DoH)
+	// queries. If not specified, POST is used.
+	DoHMethod string
+
+	// Retries is the number of times a query is resubmitted if submitting the
+	// query fails (e.g., the query times out). If not specified, failed
+	// queries are not retried.
+	Retries int
+
+	// RetryBackoff is the amount of time to wait before the first retry
+	// attempt. This delay is doubled for each additional retry attempt, up
+	// to maxRetryBackoff.
+	RetryBackoff time.Duration
+
+	// RetryJitter is the maximum random amount of time added to each retry
+	// delay in order to avoid resubmitting queries in lockstep.
+	RetryJitter time.Duration
+}
+
+// NewTLSConfig creates a TLS configuration for use with encrypted transports.
+// If specified, the PEM-encoded CA certificates in the given file are used to
+// verify server certificates instead of the system certificate pool. An error
+// is returned if the CA bundle cannot be loaded.
+func NewTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+
+	tlsConfig := tls.Config{
+		MinVersion: tls.VersionTLS12,
+
+		// #nosec G402
+		// Explicitly requested by the user; intended for use with test
+		// resolvers using self-signed certificates.
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caFile != "" {
+		pemData, err := os.ReadFile(filepath.Clean(caFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %q: %w", caFile, err)
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle %q", caFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	return &tlsConfig, nil
+}
+
+// tlsConfig returns a copy of the base TLS configuration with the server name
+// used for certificate verification applied.
+func (opts QueryOptions) tlsConfig(server string) *tls.Config {
+
+	var tlsConfig *tls.Config
+	switch {
+	case opts.TLSConfig != nil:
+		tlsConfig = opts.TLSConfig.Clone()
+	default:
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	switch {
+	case opts.TLSServerName != "":
+		tlsConfig.ServerName = opts.TLSServerName
+	default:
+		tlsConfig.ServerName = server
+	}
+
+	return tlsConfig
+}
+
+// splitServerAddress returns the host and host:port address for the given
+// server. The specified default port is used if the server does not include
+// a port. IPv6 addresses may be provided with or without enclosing square
+// brackets if a port is not included.
+func splitServerAddress(server string, defaultPort string) (string, string) {
+
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		host = strings.Trim(server, "[]")
+		port = defaultPort
+	}
+
+	return host, net.JoinHostPort(host, port)
+}
+
+// newClient constructs a DNS client for the specified network so that we are
+// able to override default settings.
+func newClient(network string, timeout time.Duration) *dns.Client {
+	return &dns.Client{
+		Net:     network,
+		Timeout: timeout,
+	}
+}
+
+// exchangeResult records the details of a completed message exchange with a
+// DNS server.
+type exchangeResult struct {
+
+	// response is the message returned by the DNS server.
+	response *dns.Msg
+
+	// rtt is the round-trip time for the exchange.
+	rtt time.Duration
+
+	// transport is the network transport which produced the response.
+	transport string
+
+	// httpStatus is the HTTP status code returned by a DNS-over-HTTPS
+	// server. This is zero for other transports.
+	httpStatus int
+}
+
+// exchange submits the given message to the specified server using the
+// requested transport. The details of the exchange, including the transport
+// actually used to obtain the response, are returned along with any error
+// which occurred.
+//
+// Servers specified as https:// URLs are queried using DNS-over-HTTPS and
+// servers specified using the quic:// scheme are queried using DNS-over-QUIC
+// regardless of the requested transport.
+//
+// If the automatic transport is requested and the UDP response is truncated,
+// the query is resubmitted using TCP. The returned round-trip time is the
+// combined time for both attempts.
+func exchange(msg *dns.Msg, server string, opts QueryOptions) (exchangeResult, error) {
+
+	switch {
+	case isDoHServer(server):
+		return exchangeDoH(msg, server, opts)
+	case isDoQServer(server):
+		return exchangeDoQ(msg, server, opts)
+	}
+
+	_, remoteAddress := splitServerAddress(server, defaultDNSPort)
+
+	switch opts.Transport {
+	case TransportTLS:
+		host, dotAddress := splitServerAddress(server, defaultDoTPort)
+		client := newClient("tcp-tls", opts.Timeout)
+		client.TLSConfig = opts.tlsConfig(host)
+		in, rtt, err := client.Exchange(msg, dotAddress)
+		return exchangeResult{response: in, rtt: rtt, transport: TransportTLS}, err
+
+	case TransportTCP:
+		in, rtt, err := newClient(TransportTCP, opts.Timeout).Exchange(msg, remoteAddress)
+		return exchangeResult{response: in, rtt: rtt, transport: TransportTCP}, err
+
+	case TransportAuto:
+		in, rtt, err := newClient(TransportUDP, opts.Timeout).Exchange(msg, remoteAddress)
+		if err != nil || !in.Truncated {
+			return exchangeResult{response: in, rtt: rtt, transport: TransportUDP}, err
+		}
+
+		tcpIn, tcpRTT, err := newClient(TransportTCP, opts.Timeout).Exchange(msg, remoteAddress)
+		return exchangeResult{response: tcpIn, rtt: rtt + tcpRTT, transport: TransportTCP}, err
+
+	default:
+		in, rtt, err := newClient(TransportUDP, opts.Timeout).Exchange(msg, remoteAddress)
+		return exchangeResult{response: in, rtt: rtt, transport: TransportUDP}, err
+	}
+}
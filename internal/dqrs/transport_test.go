@@ -0,0 +1,413 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// testServerName is the name included in the certificate presented by the
+// local test DNS servers.
+const testServerName string = "dns.test"
+
+// testAnswer is the IP Address returned by the local test DNS servers for
+// all A record queries.
+const testAnswer string = "192.0.2.53"
+
+// newTestCertificate generates a self-signed certificate for the local test
+// DNS servers and writes it to a PEM-encoded CA bundle in a temporary
+// directory. The certificate and path to the CA bundle are returned.
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: testServerName},
+		DNSNames:              []string{testServerName},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(caFile, pemData, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	cert := tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+
+	return cert, caFile
+}
+
+// testReply builds the reply to the given query returned by the local test
+// DNS servers.
+func testReply(r *dns.Msg) *dns.Msg {
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Answer = append(m.Answer, &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   r.Question[0].Name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    300,
+		},
+		A: net.ParseIP(testAnswer),
+	})
+
+	return m
+}
+
+// testQuery builds a query for an A record using a fixed, non-zero message
+// ID.
+func testQuery() *dns.Msg {
+
+	msg := new(dns.Msg)
+	msg.SetQuestion("www.example.com.", dns.TypeA)
+	msg.Id = 0xbeef
+
+	return msg
+}
+
+// startTestDoTServer starts a local DNS-over-TLS (DoT) server presenting the
+// given certificate. The address of the server is returned along with a
+// function which returns the message IDs of all queries received.
+func startTestDoTServer(t *testing.T, cert tls.Certificate) (string, func() []uint16) {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatalf("failed to start DoT listener: %v", err)
+	}
+
+	var mu sync.Mutex
+	var ids []uint16
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Net:               "tcp-tls",
+		Listener:          listener,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			mu.Lock()
+			ids = append(ids, r.Id)
+			mu.Unlock()
+
+			_ = w.WriteMsg(testReply(r))
+		}),
+	}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	return listener.Addr().String(), func() []uint16 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uint16(nil), ids...)
+	}
+}
+
+func TestExchangeDoT(t *testing.T) {
+
+	cert, caFile := newTestCertificate(t)
+	address, receivedIDs := startTestDoTServer(t, cert)
+
+	tlsConfig, err := NewTLSConfig(caFile, false)
+	if err != nil {
+		t.Fatalf("failed to create TLS config: %v", err)
+	}
+
+	msg := testQuery()
+	result, err := exchange(msg, address, QueryOptions{
+		Timeout:       5 * time.Second,
+		Transport:     TransportTLS,
+		TLSConfig:     tlsConfig,
+		TLSServerName: testServerName,
+	})
+	if err != nil {
+		t.Fatalf("DoT exchange failed: %v", err)
+	}
+
+	if result.transport != TransportTLS {
+		t.Errorf("got transport %q, want %q", result.transport, TransportTLS)
+	}
+
+	if result.response.Id != msg.Id {
+		t.Errorf("got response message ID %#x, want %#x", result.response.Id, msg.Id)
+	}
+
+	if ids := receivedIDs(); len(ids) != 1 || ids[0] != msg.Id {
+		t.Errorf("got query message IDs %#x, want [%#x]", ids, msg.Id)
+	}
+
+	if len(result.response.Answer) != 1 {
+		t.Fatalf("got %d answer records, want 1", len(result.response.Answer))
+	}
+
+	a, ok := result.response.Answer[0].(*dns.A)
+	if !ok || a.A.String() != testAnswer {
+		t.Errorf("got answer %v, want A %s", result.response.Answer[0], testAnswer)
+	}
+}
+
+func TestExchangeDoTUntrustedCertificate(t *testing.T) {
+
+	cert, _ := newTestCertificate(t)
+	address, _ := startTestDoTServer(t, cert)
+
+	// The self-signed certificate is not trusted without the CA bundle.
+	tlsConfig, err := NewTLSConfig("", false)
+	if err != nil {
+		t.Fatalf("failed to create TLS config: %v", err)
+	}
+
+	_, err = exchange(testQuery(), address, QueryOptions{
+		Timeout:       5 * time.Second,
+		Transport:     TransportTLS,
+		TLSConfig:     tlsConfig,
+		TLSServerName: testServerName,
+	})
+	if err == nil {
+		t.Fatal("DoT exchange succeeded using an untrusted certificate")
+	}
+}
+
+// testTruncateDelay is how long the local truncating test server waits
+// before answering each query. It provides a lower bound for the combined
+// round-trip time of a query which falls back from UDP to TCP.
+const testTruncateDelay = 50 * time.Millisecond
+
+// startTestTruncatingServer starts a local DNS server listening for UDP and
+// TCP on the same address. Responses sent over UDP are truncated with no
+// answer records, while responses sent over TCP are complete. The address of
+// the server is returned along with a function which returns the network of
+// each query received.
+func startTestTruncatingServer(t *testing.T) (string, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var networks []string
+
+	handler := func(network string) dns.Handler {
+		return dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			mu.Lock()
+			networks = append(networks, network)
+			mu.Unlock()
+
+			time.Sleep(testTruncateDelay)
+
+			reply := testReply(r)
+			if network == TransportUDP {
+				reply.Answer = nil
+				reply.Truncated = true
+			}
+			_ = w.WriteMsg(reply)
+		})
+	}
+
+	// Bind TCP to an ephemeral port first and then UDP to the same port,
+	// retrying if the UDP port happens to be in use.
+	var listener net.Listener
+	var packetConn net.PacketConn
+	for attempt := 0; ; attempt++ {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start TCP listener: %v", err)
+		}
+
+		packetConn, err = net.ListenPacket("udp", listener.Addr().String())
+		if err == nil {
+			break
+		}
+
+		_ = listener.Close()
+		if attempt == 10 {
+			t.Fatalf("failed to start UDP listener: %v", err)
+		}
+	}
+
+	servers := []*dns.Server{
+		{Net: TransportUDP, PacketConn: packetConn, Handler: handler(TransportUDP)},
+		{Net: TransportTCP, Listener: listener, Handler: handler(TransportTCP)},
+	}
+
+	for _, server := range servers {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+
+		go func(server *dns.Server) {
+			_ = server.ActivateAndServe()
+		}(server)
+		<-started
+
+		t.Cleanup(func() {
+			_ = server.Shutdown()
+		})
+	}
+
+	return listener.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), networks...)
+	}
+}
+
+func TestExchangeAutoFallback(t *testing.T) {
+
+	address, receivedNetworks := startTestTruncatingServer(t)
+
+	result, err := exchange(testQuery(), address, QueryOptions{
+		Timeout:   5 * time.Second,
+		Transport: TransportAuto,
+	})
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	if result.transport != TransportTCP {
+		t.Errorf("got transport %q, want %q", result.transport, TransportTCP)
+	}
+
+	networks := receivedNetworks()
+	if len(networks) != 2 || networks[0] != TransportUDP || networks[1] != TransportTCP {
+		t.Errorf("got queries over %v, want [%s %s]", networks, TransportUDP, TransportTCP)
+	}
+
+	if result.response.Truncated {
+		t.Error("got truncated response after falling back to TCP")
+	}
+
+	if len(result.response.Answer) != 1 {
+		t.Fatalf("got %d answer records, want 1", len(result.response.Answer))
+	}
+
+	a, ok := result.response.Answer[0].(*dns.A)
+	if !ok || a.A.String() != testAnswer {
+		t.Errorf("got answer %v, want A %s", result.response.Answer[0], testAnswer)
+	}
+
+	// The round-trip time covers both the UDP and the TCP attempts.
+	if want := 2 * testTruncateDelay; result.rtt < want {
+		t.Errorf("got round-trip time %v, want at least %v", result.rtt, want)
+	}
+}
+
+func TestExchangeUDPTruncated(t *testing.T) {
+
+	address, receivedNetworks := startTestTruncatingServer(t)
+
+	result, err := exchange(testQuery(), address, QueryOptions{
+		Timeout:   5 * time.Second,
+		Transport: TransportUDP,
+	})
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	if result.transport != TransportUDP {
+		t.Errorf("got transport %q, want %q", result.transport, TransportUDP)
+	}
+
+	if networks := receivedNetworks(); len(networks) != 1 {
+		t.Errorf("got queries over %v, want [%s]", networks, TransportUDP)
+	}
+
+	if !result.response.Truncated {
+		t.Error("got response without the TC flag over UDP")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+
+	_, caFile := newTestCertificate(t)
+
+	invalidFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write invalid CA bundle: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		caFile      string
+		insecure    bool
+		wantErr     bool
+		wantRootCAs bool
+	}{
+		{name: "system pool", caFile: ""},
+		{name: "insecure", caFile: "", insecure: true},
+		{name: "CA bundle", caFile: caFile, wantRootCAs: true},
+		{name: "missing CA bundle", caFile: filepath.Join(t.TempDir(), "missing.pem"), wantErr: true},
+		{name: "invalid CA bundle", caFile: invalidFile, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := NewTLSConfig(tt.caFile, tt.insecure)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tlsConfig.InsecureSkipVerify != tt.insecure {
+				t.Errorf("got InsecureSkipVerify %t, want %t", tlsConfig.InsecureSkipVerify, tt.insecure)
+			}
+
+			if (tlsConfig.RootCAs != nil) != tt.wantRootCAs {
+				t.Errorf("got RootCAs set %t, want %t", tlsConfig.RootCAs != nil, tt.wantRootCAs)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// changeMarker flags query results which changed since the previous run when
+// repeatedly running the same queries.
+const changeMarker = "~"
+
+// ttlTolerance is the allowed difference between the TTL of a record and the
+// TTL expected by counting down the TTL of the same record from the previous
+// run. This accounts for TTLs being truncated to whole seconds.
+const ttlTolerance = 2 * time.Second
+
+// fingerprint returns a representation of the answer provided by the DNS
+// server, excluding record TTLs, and whether the query failed which may be
+// compared against the answer provided by the same DNS server for the same
+// query in a previous run.
+func (dqr DNSQueryResponse) fingerprint() string {
+
+	// Error messages may include details which differ for each query (e.g.,
+	// the local port used), so the outcome is compared instead.
+	outcome := "ok"
+	if dqr.QueryError != nil {
+		outcome = "error"
+	}
+
+	return dqr.answerSet() + "\n" + outcome
+}
+
+// recordTTLs returns the TTL of each record in the answer section indexed by
+// record type and value.
+func (dqr DNSQueryResponse) recordTTLs() map[string]time.Duration {
+
+	records := dqr.SectionRecords(SectionAnswer)
+	ttls := make(map[string]time.Duration, len(records))
+	for _, record := range records {
+		key := fmt.Sprintf("%s %s", record.Type, strings.ToLower(record.Value))
+		ttls[key] = time.Duration(record.TTL) * time.Second
+	}
+
+	return ttls
+}
+
+// ttlChanged indicates whether the TTL of any record in the answer section
+// differs from the TTL expected by counting down the TTL of the same record
+// in the given previous response by the time elapsed between the two
+// responses. Caching DNS servers count down the TTL of cached records while
+// authoritative DNS servers return the same TTL, so neither is considered a
+// change. A TTL which increased before the record was expected to expire
+// from the cache (or which decreased faster than expected) is a change.
+func (dqr DNSQueryResponse) ttlChanged(previous DNSQueryResponse) bool {
+
+	elapsed := dqr.ReceivedAt.Sub(previous.ReceivedAt)
+	previousTTLs := previous.recordTTLs()
+
+	for key, ttl := range dqr.recordTTLs() {
+		previousTTL, ok := previousTTLs[key]
+		if !ok {
+			continue
+		}
+
+		expected := previousTTL - elapsed
+		switch {
+		case ttl == previousTTL:
+			// Unchanged TTL as returned by authoritative DNS servers.
+		case expected <= 0:
+			// The record was expected to expire from the cache and be
+			// refreshed with a new TTL.
+		case ttl > expected+ttlTolerance, ttl < expected-ttlTolerance:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Changed indicates for each query response whether the answer, error status
+// or record TTLs (see ttlChanged) differ from the response provided by the
+// same DNS server for the same query in the given previous results. Query
+// responses without a previous response are not considered changed.
+func (dqrs DNSQueryResponses) Changed(previous DNSQueryResponses) map[ResponseKey]bool {
+
+	responses := make(map[ResponseKey]DNSQueryResponse, len(previous))
+	for _, item := range previous {
+		responses[item.ResponseKey()] = item
+	}
+
+	changed := make(map[ResponseKey]bool, len(dqrs))
+	for _, item := range dqrs {
+		response, ok := responses[item.ResponseKey()]
+		changed[item.ResponseKey()] = ok &&
+			(response.fingerprint() != item.fingerprint() || item.ttlChanged(response))
+	}
+
+	return changed
+}
+
+// countTrue returns the number of entries in the given map which are true.
+func countTrue(m map[ResponseKey]bool) int {
+
+	var count int
+	for _, value := range m {
+		if value {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package dqrs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// withTTL returns a copy of the query response received at the given time
+// with the TTL of all answer records set to the given value.
+func withTTL(dqr DNSQueryResponse, receivedAt time.Time, ttl uint32) DNSQueryResponse {
+
+	dqr.ReceivedAt = receivedAt
+	answer := make([]dns.RR, 0, len(dqr.Answer))
+	for _, rr := range dqr.Answer {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = ttl
+		answer = append(answer, rr)
+	}
+	dqr.Answer = answer
+
+	return dqr
+}
+
+func TestChanged(t *testing.T) {
+
+	start := time.Now()
+	later := start.Add(30 * time.Second)
+
+	tests := []struct {
+		name     string
+		previous DNSQueryResponse
+		current  DNSQueryResponse
+		want     bool
+	}{
+		{
+			name:     "unchanged authoritative TTL",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 300),
+			current:  withTTL(testResponse("s1", "192.0.2.1"), later, 300),
+			want:     false,
+		},
+		{
+			name:     "cached TTL counting down",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 300),
+			current:  withTTL(testResponse("s1", "192.0.2.1"), later, 269),
+			want:     false,
+		},
+		{
+			name:     "cached record refreshed after expiry",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 20),
+			current:  withTTL(testResponse("s1", "192.0.2.1"), later, 300),
+			want:     false,
+		},
+		{
+			name:     "TTL increased before expiry",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 200),
+			current:  withTTL(testResponse("s1", "192.0.2.1"), later, 290),
+			want:     true,
+		},
+		{
+			name:     "TTL decreased faster than expected",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 300),
+			current:  withTTL(testResponse("s1", "192.0.2.1"), later, 100),
+			want:     true,
+		},
+		{
+			name:     "answer changed",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 300),
+			current:  withTTL(testResponse("s1", "192.0.2.2"), later, 300),
+			want:     true,
+		},
+		{
+			name:     "query failed",
+			previous: withTTL(testResponse("s1", "192.0.2.1"), start, 300),
+			current:  testFailedResponse("s1", false, 0, errors.New("i/o timeout")),
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := DNSQueryResponses{tt.current}
+			changed := current.Changed(DNSQueryResponses{tt.previous})
+
+			if got := changed[tt.current.ResponseKey()]; got != tt.want {
+				t.Errorf("got changed %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedSameAddressDifferentTransports(t *testing.T) {
+
+	start := time.Now()
+	later := start.Add(30 * time.Second)
+
+	// The same address is queried using UDP and DNS-over-TLS, with each
+	// providing a different (but unchanged) answer between runs.
+	entry := func(id string, transport string, address string, at time.Time) DNSQueryResponse {
+		dqr := withTTL(testResponse("192.0.2.53", address), at, 300)
+		dqr.ServerID = id
+		dqr.Transport = transport
+		return dqr
+	}
+
+	previous := DNSQueryResponses{
+		entry("tls://192.0.2.53#b", TransportTLS, "192.0.2.2", start),
+		entry("udp://192.0.2.53#a", TransportUDP, "192.0.2.1", start),
+	}
+	current := DNSQueryResponses{
+		entry("udp://192.0.2.53#a", TransportUDP, "192.0.2.1", later),
+		entry("tls://192.0.2.53#b", TransportTLS, "192.0.2.2", later),
+	}
+
+	changed := current.Changed(previous)
+	if len(changed) != len(current) {
+		t.Fatalf("got %d results, want %d", len(changed), len(current))
+	}
+
+	for _, item := range current {
+		if changed[item.ResponseKey()] {
+			t.Errorf("%s: result flagged as changed", item.ServerID)
+		}
+	}
+}
+
+func TestChangedWithoutPrevious(t *testing.T) {
+
+	current := DNSQueryResponses{testResponse("s1", "192.0.2.1")}
+	if countTrue(current.Changed(nil)) != 0 {
+		t.Error("results flagged as changed without a previous run")
+	}
+}
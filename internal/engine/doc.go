@@ -0,0 +1,10 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+// Package engine provides types and functions used by this application to
+// submit batches of DNS queries using a bounded pool of workers.
+package engine
@@ -0,0 +1,218 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package engine
+
+import (
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/atc0005/dnsc/internal/dqrs"
+)
+
+// Job is a single DNS query to submit to a DNS server.
+type Job struct {
+
+	// Query is the FQDN that we request a record for.
+	Query string
+
+	// Server is the address of the DNS server the query is submitted to.
+	Server string
+
+	// ServerID uniquely identifies the DNS server entry the query is
+	// submitted to. This differs from Server if the same address was
+	// specified more than once using different transports or labels.
+	ServerID string
+
+	// ServerLabel is an optional friendly name for the DNS server.
+	ServerLabel string
+
+	// ServerHostname is the hostname originally specified for the DNS
+	// server if it was resolved from a hostname.
+	ServerHostname string
+
+	// RecordType is the type of record requested as part of the query.
+	RecordType uint16
+
+	// RateLimit is the maximum number of queries per second submitted to the
+	// DNS server. A value of zero indicates no per-server rate limit.
+	RateLimit float64
+
+	// Options is the collection of settings applied when submitting the
+	// query.
+	Options dqrs.QueryOptions
+}
+
+// Engine submits DNS queries using a bounded pool of workers. The number of
+// queries in-flight at any one time is limited overall and optionally for
+// each DNS server. The rate at which queries are submitted may also be
+// limited overall and for each DNS server.
+type Engine struct {
+
+	// maxConcurrency is the maximum number of queries in-flight at any one
+	// time.
+	maxConcurrency int
+
+	// maxPerServer is the maximum number of queries in-flight to any single
+	// DNS server at any one time. A value of zero indicates no per-server
+	// limit.
+	maxPerServer int
+
+	// rateLimit is the maximum number of queries per second submitted across
+	// all DNS servers. A value of zero indicates no overall rate limit.
+	rateLimit float64
+}
+
+// New creates a query engine which limits the number of queries in-flight to
+// the specified maximum overall and to the specified maximum for each DNS
+// server. A maximum concurrency of less than one is treated as one. A
+// per-server maximum of zero disables the per-server limit. Queries are
+// submitted at no more than the specified number of queries per second; a
+// rate limit of zero disables the overall rate limit.
+func New(maxConcurrency int, maxPerServer int, rateLimit float64) *Engine {
+
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	if maxPerServer < 0 {
+		maxPerServer = 0
+	}
+
+	return &Engine{
+		maxConcurrency: maxConcurrency,
+		maxPerServer:   maxPerServer,
+		rateLimit:      rateLimit,
+	}
+}
+
+// Run submits all given jobs and sends the response for each on the results
+// channel. Jobs are dispatched in round-robin order across DNS servers so
+// that a per-server limit for one server does not hold up queries to other
+// servers. Run blocks until all jobs have completed; the results channel is
+// not closed.
+func (e *Engine) Run(jobs []Job, results chan<- dqrs.DNSQueryResponse) {
+
+	// Setup a semaphore for each DNS server if limiting per-server
+	// concurrency.
+	serverSlots := make(map[string]chan struct{})
+	if e.maxPerServer > 0 {
+		for _, job := range jobs {
+			if _, ok := serverSlots[job.Server]; !ok {
+				serverSlots[job.Server] = make(chan struct{}, e.maxPerServer)
+			}
+		}
+	}
+
+	// Setup a rate limiter for each DNS server with a per-server rate limit.
+	// Jobs for the same DNS server share the same rate limit.
+	serverLimiters := make(map[string]*rateLimiter)
+	for _, job := range jobs {
+		if _, ok := serverLimiters[job.Server]; !ok && job.RateLimit > 0 {
+			serverLimiters[job.Server] = newRateLimiter(job.RateLimit)
+		}
+	}
+	globalLimiter := newRateLimiter(e.rateLimit)
+
+	workers := e.maxConcurrency
+	if len(jobs) < workers {
+		workers = len(jobs)
+	}
+
+	log.Debugf(
+		"Submitting %d queries using %d workers (per-server limit: %d, rate limit: %v qps)",
+		len(jobs),
+		workers,
+		e.maxPerServer,
+		e.rateLimit,
+	)
+
+	jobsChan := make(chan Job)
+
+	var workersWG sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+
+			for job := range jobsChan {
+				slots, limited := serverSlots[job.Server]
+				if limited {
+					slots <- struct{}{}
+				}
+
+				// Pace the query only after reserving a per-server slot so
+				// that queries waiting on a slot are not released together
+				// once a slot frees up. The per-server rate limit is honored
+				// before the overall rate limit so that a token reserved from
+				// the overall rate limit is not held while waiting on a DNS
+				// server with a lower rate limit.
+				delay := serverLimiters[job.Server].wait()
+				delay += globalLimiter.wait()
+
+				log.Debugf("Submitting query for %q of type %d to %q (pacing delay: %v)",
+					job.Query, job.RecordType, job.Server, delay)
+
+				result := job.perform()
+				result.PacingDelay = delay
+
+				results <- result
+
+				if limited {
+					<-slots
+				}
+
+				log.Debug("Query completed, results sent back on channel")
+			}
+		}()
+	}
+
+	for _, job := range interleave(jobs) {
+		jobsChan <- job
+	}
+	close(jobsChan)
+
+	workersWG.Wait()
+}
+
+// perform submits the query for this job and returns the response with the
+// DNS server details recorded.
+func (j Job) perform() dqrs.DNSQueryResponse {
+	result := dqrs.PerformQuery(j.Query, j.Server, j.RecordType, j.Options)
+	result.ServerID = j.ServerID
+	result.ServerLabel = j.ServerLabel
+	result.ServerHostname = j.ServerHostname
+
+	return result
+}
+
+// interleave reorders the given jobs so that consecutive jobs target
+// different DNS servers where possible. The relative order of jobs for each
+// DNS server is retained.
+func interleave(jobs []Job) []Job {
+
+	var servers []string
+	jobsByServer := make(map[string][]Job)
+	for _, job := range jobs {
+		if _, ok := jobsByServer[job.Server]; !ok {
+			servers = append(servers, job.Server)
+		}
+		jobsByServer[job.Server] = append(jobsByServer[job.Server], job)
+	}
+
+	ordered := make([]Job, 0, len(jobs))
+	for len(ordered) < len(jobs) {
+		for _, server := range servers {
+			if pending := jobsByServer[server]; len(pending) > 0 {
+				ordered = append(ordered, pending[0])
+				jobsByServer[server] = pending[1:]
+			}
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package engine
+
+import (
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atc0005/dnsc/internal/dqrs"
+	"github.com/miekg/dns"
+)
+
+// testQueryDelay is how long the local test DNS servers wait before
+// answering each query. This keeps queries in-flight long enough for
+// concurrent queries to overlap.
+const testQueryDelay = 50 * time.Millisecond
+
+// inFlightTracker records the number of queries in-flight overall and for
+// each local test DNS server along with the highest counts observed.
+type inFlightTracker struct {
+	mu sync.Mutex
+
+	current       int
+	max           int
+	currentServer map[string]int
+	maxServer     map[string]int
+}
+
+func newInFlightTracker() *inFlightTracker {
+	return &inFlightTracker{
+		currentServer: make(map[string]int),
+		maxServer:     make(map[string]int),
+	}
+}
+
+func (ift *inFlightTracker) start(server string) {
+	ift.mu.Lock()
+	defer ift.mu.Unlock()
+
+	ift.current++
+	ift.max = max(ift.max, ift.current)
+
+	ift.currentServer[server]++
+	ift.maxServer[server] = max(ift.maxServer[server], ift.currentServer[server])
+}
+
+func (ift *inFlightTracker) done(server string) {
+	ift.mu.Lock()
+	defer ift.mu.Unlock()
+
+	ift.current--
+	ift.currentServer[server]--
+}
+
+// highest returns the highest number of queries in-flight overall.
+func (ift *inFlightTracker) highest() int {
+	ift.mu.Lock()
+	defer ift.mu.Unlock()
+
+	return ift.max
+}
+
+// highestServer returns the highest number of queries in-flight to the
+// given server.
+func (ift *inFlightTracker) highestServer(server string) int {
+	ift.mu.Lock()
+	defer ift.mu.Unlock()
+
+	return ift.maxServer[server]
+}
+
+// startTestServer starts a local DNS server which answers queries over UDP
+// with an A record after a short delay, recording the queries in-flight
+// using the given tracker. The address of the server is returned.
+func startTestServer(t *testing.T, tracker *inFlightTracker) string {
+	t.Helper()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start UDP listener: %v", err)
+	}
+	address := packetConn.LocalAddr().String()
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Net:               "udp",
+		PacketConn:        packetConn,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			tracker.start(address)
+			time.Sleep(testQueryDelay)
+			tracker.done(address)
+
+			reply := new(dns.Msg)
+			reply.SetReply(r)
+			reply.Answer = append(reply.Answer, &dns.A{
+				Hdr: dns.RR_Header{
+					Name:   r.Question[0].Name,
+					Rrtype: dns.TypeA,
+					Class:  dns.ClassINET,
+					Ttl:    300,
+				},
+				A: net.ParseIP("192.0.2.53"),
+			})
+			_ = w.WriteMsg(reply)
+		}),
+	}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	return address
+}
+
+// testJobs returns the given number of jobs for each of the given DNS
+// servers.
+func testJobs(servers []string, perServer int) []Job {
+	var jobs []Job
+	for _, server := range servers {
+		for i := 0; i < perServer; i++ {
+			jobs = append(jobs, Job{
+				Query:      "www.example.com",
+				Server:     server,
+				ServerID:   server,
+				RecordType: dns.TypeA,
+				Options:    dqrs.QueryOptions{Timeout: 5 * time.Second},
+			})
+		}
+	}
+
+	return jobs
+}
+
+// runJobs submits the given jobs using the given engine and returns the
+// results received.
+func runJobs(e *Engine, jobs []Job) []dqrs.DNSQueryResponse {
+	results := make(chan dqrs.DNSQueryResponse, len(jobs))
+	e.Run(jobs, results)
+	close(results)
+
+	collected := make([]dqrs.DNSQueryResponse, 0, len(jobs))
+	for result := range results {
+		collected = append(collected, result)
+	}
+
+	return collected
+}
+
+func TestRunMaxConcurrency(t *testing.T) {
+
+	const maxConcurrency = 3
+
+	tracker := newInFlightTracker()
+	servers := []string{
+		startTestServer(t, tracker),
+		startTestServer(t, tracker),
+	}
+
+	jobs := testJobs(servers, 6)
+	results := runJobs(New(maxConcurrency, 0, 0), jobs)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("got %d results, want %d", len(results), len(jobs))
+	}
+
+	for _, result := range results {
+		if result.QueryError != nil {
+			t.Errorf("query to %s failed: %v", result.Server, result.QueryError)
+		}
+	}
+
+	if got := tracker.highest(); got > maxConcurrency {
+		t.Errorf("got %d queries in-flight, want at most %d", got, maxConcurrency)
+	}
+
+	if got := tracker.highest(); got < 2 {
+		t.Errorf("got %d queries in-flight, want queries submitted concurrently", got)
+	}
+}
+
+func TestRunMaxPerServer(t *testing.T) {
+
+	const maxPerServer = 2
+
+	tracker := newInFlightTracker()
+	servers := []string{
+		startTestServer(t, tracker),
+		startTestServer(t, tracker),
+		startTestServer(t, tracker),
+	}
+
+	jobs := testJobs(servers, 6)
+	results := runJobs(New(len(jobs), maxPerServer, 0), jobs)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("got %d results, want %d", len(results), len(jobs))
+	}
+
+	for _, server := range servers {
+		if got := tracker.highestServer(server); got > maxPerServer {
+			t.Errorf("got %d queries in-flight to %s, want at most %d", got, server, maxPerServer)
+		}
+	}
+
+	// The per-server limit for one DNS server should not hold up queries to
+	// other DNS servers.
+	if got := tracker.highest(); got <= maxPerServer {
+		t.Errorf("got %d queries in-flight overall, want more than %d", got, maxPerServer)
+	}
+}
+
+func TestRunRateLimit(t *testing.T) {
+
+	const queriesPerSecond = 20
+
+	tracker := newInFlightTracker()
+	servers := []string{startTestServer(t, tracker)}
+
+	jobs := testJobs(servers, 4)
+	for i := range jobs {
+		jobs[i].RateLimit = queriesPerSecond
+	}
+
+	start := time.Now()
+	results := runJobs(New(len(jobs), 0, 0), jobs)
+	elapsed := time.Since(start)
+
+	if len(results) != len(jobs) {
+		t.Fatalf("got %d results, want %d", len(results), len(jobs))
+	}
+
+	interval := time.Second / queriesPerSecond
+	if want := time.Duration(len(jobs)-1) * interval; elapsed < want {
+		t.Errorf("got %d queries in %v, want at least %v", len(jobs), elapsed, want)
+	}
+
+	// All but the first query wait for the per-server rate limit and the
+	// wait is recorded for each.
+	var paced int
+	for _, result := range results {
+		if result.PacingDelay > 0 {
+			paced++
+		}
+	}
+
+	if want := len(jobs) - 1; paced != want {
+		t.Errorf("got %d queries with a pacing delay, want %d", paced, want)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+
+	jobs := []Job{
+		{Server: "a", Query: "1"},
+		{Server: "a", Query: "2"},
+		{Server: "a", Query: "3"},
+		{Server: "b", Query: "1"},
+		{Server: "c", Query: "1"},
+		{Server: "c", Query: "2"},
+	}
+
+	want := []Job{
+		{Server: "a", Query: "1"},
+		{Server: "b", Query: "1"},
+		{Server: "c", Query: "1"},
+		{Server: "a", Query: "2"},
+		{Server: "c", Query: "2"},
+		{Server: "a", Query: "3"},
+	}
+
+	if got := interleave(jobs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := interleave(nil); len(got) != 0 {
+		t.Errorf("got %+v for no jobs, want none", got)
+	}
+}
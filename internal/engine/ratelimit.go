@@ -0,0 +1,81 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter paces query submission using a token bucket. The bucket holds
+// at most a single token so that queries are evenly spaced instead of being
+// submitted in bursts.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	// rate is the number of tokens added to the bucket per second.
+	rate float64
+
+	// tokens is the number of tokens currently available. This value is
+	// negative when callers are waiting on tokens which have already been
+	// reserved.
+	tokens float64
+
+	// last is the time that tokens were last added to the bucket.
+	last time.Time
+}
+
+// newRateLimiter creates a rate limiter which permits the specified number of
+// queries per second. A nil rate limiter is returned if the rate is not
+// positive, indicating that no limit is applied.
+func newRateLimiter(queriesPerSecond float64) *rateLimiter {
+
+	if queriesPerSecond <= 0 {
+		return nil
+	}
+
+	return &rateLimiter{
+		rate:   queriesPerSecond,
+		tokens: 1,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available and returns the amount of time
+// spent waiting. A nil rate limiter does not block.
+func (rl *rateLimiter) wait() time.Duration {
+
+	if rl == nil {
+		return 0
+	}
+
+	rl.mu.Lock()
+
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > 1 {
+		rl.tokens = 1
+	}
+	rl.last = now
+
+	// Reserve a token, waiting for the bucket to refill if one is not
+	// available.
+	rl.tokens--
+	var delay time.Duration
+	if rl.tokens < 0 {
+		delay = time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+	}
+
+	rl.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	return delay
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Adam Chalkley
+//
+// https://github.com/atc0005/dnsc
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+
+	const (
+		queriesPerSecond = 20
+		waits            = 5
+	)
+
+	rl := newRateLimiter(queriesPerSecond)
+
+	start := time.Now()
+	var delays []time.Duration
+	for i := 0; i < waits; i++ {
+		delays = append(delays, rl.wait())
+	}
+	elapsed := time.Since(start)
+
+	// The first wait uses the initial token; each later wait is spaced by
+	// the interval between tokens.
+	interval := time.Second / queriesPerSecond
+	if want := (waits - 1) * interval; elapsed < want {
+		t.Errorf("got %d waits in %v, want at least %v", waits, elapsed, want)
+	}
+
+	if delays[0] != 0 {
+		t.Errorf("got delay %v for the first wait, want 0", delays[0])
+	}
+
+	var total time.Duration
+	for i, delay := range delays[1:] {
+		if delay <= 0 {
+			t.Errorf("wait %d: got delay %v, want a positive delay", i+1, delay)
+		}
+		total += delay
+	}
+
+	if total > elapsed {
+		t.Errorf("got reported delays totaling %v, more than the elapsed %v", total, elapsed)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+
+	for _, qps := range []float64{0, -1} {
+		rl := newRateLimiter(qps)
+		if rl != nil {
+			t.Errorf("newRateLimiter(%v) returned a rate limiter, want nil", qps)
+		}
+
+		if delay := rl.wait(); delay != 0 {
+			t.Errorf("got delay %v from a nil rate limiter, want 0", delay)
+		}
+	}
+}